@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-ini/ini"
+	"github.com/oracle/oci-go-sdk/v65/common"
+)
+
+// 账号认证方式
+const (
+	authApiKey            = "api_key"            // API 密钥 (默认)
+	authInstancePrincipal = "instance_principal" // 实例主体，在 OCI 实例中运行时使用
+	authSessionToken      = "session_token"      // 会话令牌，通过 oci session authenticate 生成
+)
+
+const defOCIConfigFilePath = "~/.oci/config"
+
+// 读取配置项的值。与 sec.Key 不同，配置项不存在时不会在配置中创建空的配置项
+func getKeyValue(sec *ini.Section, name string) string {
+	if key, err := sec.GetKey(name); err == nil {
+		return key.Value()
+	}
+	return ""
+}
+
+// 判断配置节是否为有效的账号配置
+func isOracleSection(sec *ini.Section) bool {
+	value := func(key string) string {
+		return getKeyValue(sec, key)
+	}
+	switch value("auth") {
+	case authInstancePrincipal:
+		return true
+	case authSessionToken:
+		return value("profile") != "" || value("config_file") != ""
+	}
+	if value("profile") != "" || value("config_file") != "" {
+		return true
+	}
+	if value("user") == "" || value("fingerprint") == "" || value("tenancy") == "" || value("region") == "" {
+		return false
+	}
+	return value("key_file") != "" || value("key") != "" || value("key_env") != ""
+}
+
+// 获取账号的 API 私钥，优先使用 ini 中的 key，其次是 key_env 环境变量，最后读取 key_file 文件
+func getPrivateKey(oracle Oracle) (string, error) {
+	if oracle.Key != "" {
+		// 单行填写时可以使用 \n 表示换行
+		return strings.ReplaceAll(oracle.Key, `\n`, "\n"), nil
+	}
+	if oracle.Key_env != "" {
+		key := os.Getenv(oracle.Key_env)
+		if key == "" {
+			return "", fmt.Errorf("环境变量 %s 为空", oracle.Key_env)
+		}
+		return strings.ReplaceAll(key, `\n`, "\n"), nil
+	}
+	if oracle.Key_file == "" {
+		return "", errors.New("未配置私钥 (key / key_env / key_file)")
+	}
+	content, err := ioutil.ReadFile(oracle.Key_file)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// 使用 OCI CLI 配置文件创建 Provider
+func getFileProvider(oracle Oracle) (common.ConfigurationProvider, error) {
+	path := oracle.Config_file
+	if path == "" {
+		path = defOCIConfigFilePath
+	}
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, path[2:])
+	}
+	profile := oracle.Profile
+	if profile == "" {
+		profile = "DEFAULT"
+	}
+	if oracle.Auth == authSessionToken {
+		return common.ConfigurationProviderForSessionTokenWithProfile(path, profile, oracle.Key_password)
+	}
+	return common.ConfigurationProviderFromFileWithProfile(path, profile, oracle.Key_password)
+}
+
+// 使用指定区域的 Provider，用于多区域账号切换区域
+type regionProvider struct {
+	common.ConfigurationProvider
+	region string
+}
+
+func (p regionProvider) Region() (string, error) {
+	return p.region, nil
+}
+
+// 补全通过配置文件或实例主体认证的账号信息
+func completeOracle(oracle *Oracle, p common.ConfigurationProvider) {
+	if oracle.Tenancy == "" {
+		oracle.Tenancy, _ = p.TenancyOCID()
+	}
+	if oracle.Region == "" {
+		oracle.Region, _ = p.Region()
+	}
+	if oracle.User == "" && oracle.Auth != authInstancePrincipal {
+		oracle.User, _ = p.UserOCID()
+	}
+	if oracle.Fingerprint == "" && (oracle.Auth == "" || oracle.Auth == authApiKey) {
+		oracle.Fingerprint, _ = p.KeyFingerprint()
+	}
+}
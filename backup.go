@@ -0,0 +1,704 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// Always Free 资源限制: 块存储(引导卷和块存储卷)共 200 GB, 卷备份共 5 个。
+const (
+	freeTierStorageGBs  = 200
+	freeTierBackupCount = 5
+)
+
+func manageBootVolumeBackupsTelegram(chatID int64) {
+	msg := tgbotapi.NewMessage(chatID, "正在获取引导卷备份数据...")
+	sentMsg, _ := bot.Send(msg)
+
+	backups, err := listBootVolumeBackups(nil)
+	if err != nil {
+		editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, "获取引导卷备份失败: "+err.Error())
+		bot.Send(editMsg)
+		return
+	}
+
+	var messageText strings.Builder
+	messageText.WriteString(fmt.Sprintf("引导卷备份 (当前账号: %s)\n\n", oracleSectionName))
+	if len(backups) == 0 {
+		messageText.WriteString("没有找到任何引导卷备份。\n")
+	} else {
+		messageText.WriteString(fmt.Sprintf("%-5s %-30s %-10s %-10s %-10s\n", "序号", "名称", "状态", "大小(GB)", "类型"))
+	}
+
+	indexes := make([]int, len(backups))
+	for i := range backups {
+		indexes[i] = i
+	}
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, i := range getPageIndexes("boot_volume_backups", indexes) {
+		backup := backups[i]
+		messageText.WriteString(fmt.Sprintf("%-5d %-30s %-10s %-10d %-10s\n",
+			i+1,
+			*backup.DisplayName,
+			getBootVolumeBackupState(backup.LifecycleState),
+			getBackupSizeInGBs(backup),
+			backup.Type))
+		button := tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("备份 %d", i+1),
+			fmt.Sprintf("boot_volume_backup_details:%d", ocidKey(backup.Id)))
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(button))
+	}
+
+	if warning := backupStorageWarning(backups); warning != "" {
+		messageText.WriteString("\n" + warning)
+	}
+
+	if row := pageButtons("boot_volume_backups", len(backups)); row != nil {
+		keyboard = append(keyboard, row)
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		refreshButton("boot_volume_backups"),
+		tgbotapi.NewInlineKeyboardButtonData("返回", "account_action:manage_storage"),
+	))
+
+	editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, messageText.String())
+	editMsg.ReplyMarkup = &tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	bot.Send(editMsg)
+}
+
+// backupKey 为 ocidKey 返回的编号，下同
+func showBootVolumeBackupDetails(chatID int64, backupKey int) {
+	backup, err := getBootVolumeBackupByKey(backupKey)
+	if err != nil {
+		sendErrorMessage(chatID, "获取引导卷备份失败: "+err.Error())
+		return
+	}
+
+	sourceType := "手动"
+	if backup.SourceType == core.BootVolumeBackupSourceTypeScheduled {
+		sourceType = "备份策略"
+	}
+	expiration := "永久保留"
+	if backup.ExpirationTime != nil {
+		expiration = backup.ExpirationTime.Local().Format("2006-01-02 15:04:05")
+	}
+
+	var messageText strings.Builder
+	messageText.WriteString("引导卷备份详情：\n\n")
+	messageText.WriteString(fmt.Sprintf("名称: %s\n", *backup.DisplayName))
+	messageText.WriteString(fmt.Sprintf("状态: %s\n", getBootVolumeBackupState(backup.LifecycleState)))
+	messageText.WriteString(fmt.Sprintf("OCID: %s\n", *backup.Id))
+	messageText.WriteString(fmt.Sprintf("类型: %s\n", backup.Type))
+	messageText.WriteString(fmt.Sprintf("来源: %s\n", sourceType))
+	if backup.SizeInGBs != nil {
+		messageText.WriteString(fmt.Sprintf("引导卷大小: %d GB\n", *backup.SizeInGBs))
+	}
+	messageText.WriteString(fmt.Sprintf("备份占用: %d GB\n", getBackupSizeInGBs(backup)))
+	messageText.WriteString(fmt.Sprintf("创建时间: %s\n", backup.TimeCreated.Local().Format("2006-01-02 15:04:05")))
+	messageText.WriteString(fmt.Sprintf("过期时间: %s\n", expiration))
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("恢复为新引导卷", fmt.Sprintf("boot_volume_backup_action:%d:restore", backupKey)),
+			tgbotapi.NewInlineKeyboardButtonData("删除备份", fmt.Sprintf("boot_volume_backup_action:%d:delete", backupKey)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("返回备份列表", "account_action:boot_volume_backups"),
+		),
+	)
+
+	msg := tgbotapi.NewMessage(chatID, messageText.String())
+	msg.ReplyMarkup = keyboard
+	bot.Send(msg)
+}
+
+func handleBootVolumeBackupAction(chatID int64, backupKey int, action string) {
+	switch action {
+	case "restore":
+		var keyboard [][]tgbotapi.InlineKeyboardButton
+		for i, ad := range availabilityDomains {
+			button := tgbotapi.NewInlineKeyboardButtonData(*ad.Name, fmt.Sprintf("restore_boot_volume_backup:%d:%d", backupKey, i))
+			keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(button))
+		}
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("返回", fmt.Sprintf("boot_volume_backup_details:%d", backupKey)),
+		))
+		msg := tgbotapi.NewMessage(chatID, "请选择新引导卷所在的可用性域：")
+		msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+		bot.Send(msg)
+	case "delete":
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("确认删除", fmt.Sprintf("confirm_delete_boot_volume_backup:%d", backupKey)),
+				tgbotapi.NewInlineKeyboardButtonData("取消", fmt.Sprintf("boot_volume_backup_details:%d", backupKey)),
+			),
+		)
+		msg := tgbotapi.NewMessage(chatID, "确定要删除此引导卷备份吗？此操作不可逆。")
+		msg.ReplyMarkup = keyboard
+		bot.Send(msg)
+	default:
+		sendErrorMessage(chatID, "未知的操作")
+	}
+}
+
+func handleRestoreBootVolumeBackup(chatID int64, backupKey, adIndex int) {
+	backup, err := getBootVolumeBackupByKey(backupKey)
+	if err != nil {
+		sendErrorMessage(chatID, "获取引导卷备份失败: "+err.Error())
+		return
+	}
+	if backup.LifecycleState != core.BootVolumeBackupLifecycleStateAvailable {
+		sendErrorMessage(chatID, "引导卷备份当前不可用，状态: "+getBootVolumeBackupState(backup.LifecycleState))
+		return
+	}
+	if adIndex < 0 || adIndex >= len(availabilityDomains) {
+		sendErrorMessage(chatID, "无效的可用性域")
+		return
+	}
+	volume, err := restoreBootVolumeBackup(backup.Id, availabilityDomains[adIndex].Name, newResourceJob())
+	if err != nil {
+		sendErrorMessage(chatID, "恢复引导卷备份失败: "+err.Error())
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("正在从备份 '%s' 恢复引导卷 '%s'，请稍后在引导卷列表中查看", *backup.DisplayName, *volume.DisplayName))
+	bot.Send(msg)
+}
+
+func handleDeleteBootVolumeBackup(chatID int64, backupKey int) {
+	backup, err := getBootVolumeBackupByKey(backupKey)
+	if err != nil {
+		sendErrorMessage(chatID, "获取引导卷备份失败: "+err.Error())
+		return
+	}
+	_, err = deleteBootVolumeBackup(storageClient, backup.Id)
+	if err != nil {
+		sendErrorMessage(chatID, "删除引导卷备份失败: "+err.Error())
+	} else {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("已成功删除引导卷备份 '%s'", *backup.DisplayName))
+		bot.Send(msg)
+	}
+	manageBootVolumeBackupsTelegram(chatID)
+}
+
+// 选择备份类型
+func promptCreateBootVolumeBackup(chatID int64, volumeIndex int) {
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("完整备份", fmt.Sprintf("create_boot_volume_backup:%d:full", volumeIndex)),
+			tgbotapi.NewInlineKeyboardButtonData("增量备份", fmt.Sprintf("create_boot_volume_backup:%d:incremental", volumeIndex)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("返回", fmt.Sprintf("boot_volume_details:%d", volumeIndex)),
+		),
+	)
+	msg := tgbotapi.NewMessage(chatID, "请选择备份类型：")
+	msg.ReplyMarkup = keyboard
+	bot.Send(msg)
+}
+
+func handleCreateBootVolumeBackup(chatID int64, volumeIndex int, backupType string) {
+	bootVolumes := getAllBootVolumes()
+	if volumeIndex < 0 || volumeIndex >= len(bootVolumes) {
+		sendErrorMessage(chatID, "无效的引导卷索引")
+		return
+	}
+	volume := bootVolumes[volumeIndex]
+
+	t := core.CreateBootVolumeBackupDetailsTypeFull
+	if backupType == "incremental" {
+		t = core.CreateBootVolumeBackupDetailsTypeIncremental
+	}
+	backup, err := createBootVolumeBackup(storageClient, volume.Id, t, newResourceJob())
+	if err != nil {
+		sendErrorMessage(chatID, "创建引导卷备份失败: "+err.Error())
+		return
+	}
+
+	text := fmt.Sprintf("正在创建引导卷备份 '%s'，请稍后在备份列表中查看", *backup.DisplayName)
+	if backups, err := listBootVolumeBackups(nil); err == nil {
+		if warning := backupStorageWarning(append(backups, backup)); warning != "" {
+			text += "\n\n" + warning
+		}
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("查看备份列表", "account_action:boot_volume_backups"),
+			tgbotapi.NewInlineKeyboardButtonData("返回", fmt.Sprintf("boot_volume_details:%d", volumeIndex)),
+		),
+	)
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = keyboard
+	bot.Send(msg)
+}
+
+// 显示引导卷当前的备份策略，并提供 Oracle 预定义策略（bronze/silver/gold）和自定义计划
+func showBootVolumeBackupPolicy(chatID int64, volumeIndex int) {
+	bootVolumes := getAllBootVolumes()
+	if volumeIndex < 0 || volumeIndex >= len(bootVolumes) {
+		sendErrorMessage(chatID, "无效的引导卷索引")
+		return
+	}
+	volume := bootVolumes[volumeIndex]
+
+	policies, err := listOracleBackupPolicies()
+	if err != nil {
+		sendErrorMessage(chatID, "获取备份策略失败: "+err.Error())
+		return
+	}
+
+	current := "未分配"
+	assignment, err := getBackupPolicyAssignment(volume.Id)
+	if err != nil {
+		current = "获取失败: " + err.Error()
+	} else if assignment != nil {
+		current = *assignment.PolicyId
+		if policy, err := getVolumeBackupPolicy(assignment.PolicyId); err == nil {
+			current = *policy.DisplayName
+		}
+	}
+
+	var messageText strings.Builder
+	messageText.WriteString(fmt.Sprintf("引导卷 '%s' 的备份策略\n\n", *volume.DisplayName))
+	messageText.WriteString(fmt.Sprintf("当前策略: %s\n\n", current))
+	messageText.WriteString("请选择要分配的备份策略：")
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for i, policy := range policies {
+		button := tgbotapi.NewInlineKeyboardButtonData(*policy.DisplayName, fmt.Sprintf("assign_backup_policy:%d:%d", volumeIndex, i))
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(button))
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("自定义计划", fmt.Sprintf("custom_backup_policy:%d", volumeIndex)),
+		tgbotapi.NewInlineKeyboardButtonData("取消分配", fmt.Sprintf("unassign_backup_policy:%d", volumeIndex)),
+	))
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("返回", fmt.Sprintf("boot_volume_details:%d", volumeIndex)),
+	))
+
+	msg := tgbotapi.NewMessage(chatID, messageText.String())
+	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	bot.Send(msg)
+}
+
+func handleAssignBackupPolicy(chatID int64, volumeIndex, policyIndex int) {
+	bootVolumes := getAllBootVolumes()
+	if volumeIndex < 0 || volumeIndex >= len(bootVolumes) {
+		sendErrorMessage(chatID, "无效的引导卷索引")
+		return
+	}
+	volume := bootVolumes[volumeIndex]
+
+	policies, err := listOracleBackupPolicies()
+	if err != nil || policyIndex < 0 || policyIndex >= len(policies) {
+		sendErrorMessage(chatID, "获取备份策略失败或策略索引无效")
+		return
+	}
+	policy := policies[policyIndex]
+
+	err = assignBackupPolicy(volume.Id, policy.Id)
+	if err != nil {
+		sendErrorMessage(chatID, "分配备份策略失败: "+err.Error())
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("已为引导卷 '%s' 分配备份策略 '%s'", *volume.DisplayName, *policy.DisplayName))
+	bot.Send(msg)
+}
+
+func handleUnassignBackupPolicy(chatID int64, volumeIndex int) {
+	bootVolumes := getAllBootVolumes()
+	if volumeIndex < 0 || volumeIndex >= len(bootVolumes) {
+		sendErrorMessage(chatID, "无效的引导卷索引")
+		return
+	}
+	volume := bootVolumes[volumeIndex]
+
+	assignment, err := getBackupPolicyAssignment(volume.Id)
+	if err != nil {
+		sendErrorMessage(chatID, "获取备份策略失败: "+err.Error())
+		return
+	}
+	if assignment == nil {
+		sendErrorMessage(chatID, "该引导卷未分配备份策略")
+		return
+	}
+	_, err = deleteBackupPolicyAssignment(assignment.Id)
+	if err != nil {
+		sendErrorMessage(chatID, "取消分配备份策略失败: "+err.Error())
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("已取消引导卷 '%s' 的备份策略", *volume.DisplayName))
+	bot.Send(msg)
+}
+
+func promptCustomBackupPolicy(chatID int64, volumeIndex int) {
+	msg := tgbotapi.NewMessage(chatID, "请输入备份周期和保留份数，以空格分隔。\n"+
+		"周期可选: daily / weekly / monthly\n"+
+		"例如: weekly 4 表示每周完整备份一次，保留 4 份。")
+	msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, Selective: true}
+	bot.Send(msg)
+	setUserState(chatID, "custom_backup_policy", volumeIndex)
+}
+
+func handleCustomBackupPolicy(chatID int64, volumeIndex int, text string) {
+	fields := strings.Fields(text)
+	if len(fields) != 2 {
+		sendErrorMessage(chatID, "输入格式错误，例如: weekly 4")
+		return
+	}
+	var period core.VolumeBackupSchedulePeriodEnum
+	var periodSeconds int
+	switch strings.ToLower(fields[0]) {
+	case "daily":
+		period, periodSeconds = core.VolumeBackupSchedulePeriodDay, 24*3600
+	case "weekly":
+		period, periodSeconds = core.VolumeBackupSchedulePeriodWeek, 7*24*3600
+	case "monthly":
+		period, periodSeconds = core.VolumeBackupSchedulePeriodMonth, 31*24*3600
+	default:
+		sendErrorMessage(chatID, "无效的备份周期，可选: daily / weekly / monthly")
+		return
+	}
+	count, err := strconv.Atoi(fields[1])
+	if err != nil || count <= 0 {
+		sendErrorMessage(chatID, "保留份数必须为正整数")
+		return
+	}
+
+	bootVolumes := getAllBootVolumes()
+	if volumeIndex < 0 || volumeIndex >= len(bootVolumes) {
+		sendErrorMessage(chatID, "无效的引导卷索引")
+		return
+	}
+	volume := bootVolumes[volumeIndex]
+
+	schedule := core.VolumeBackupSchedule{
+		BackupType:       core.VolumeBackupScheduleBackupTypeFull,
+		Period:           period,
+		RetentionSeconds: common.Int(periodSeconds * count),
+		TimeZone:         core.VolumeBackupScheduleTimeZoneUtc,
+	}
+	// 策略名称由周期和保留份数决定，已创建过相同的策略时直接使用
+	displayName := fmt.Sprintf("oci-help-%s-%d", strings.ToLower(fields[0]), count)
+	policy, err := findVolumeBackupPolicy(displayName)
+	if err != nil {
+		sendErrorMessage(chatID, "获取备份策略失败: "+err.Error())
+		return
+	}
+	if policy == nil {
+		created, err := createVolumeBackupPolicy(displayName, []core.VolumeBackupSchedule{schedule}, newResourceJob())
+		if err != nil {
+			sendErrorMessage(chatID, "创建备份策略失败: "+err.Error())
+			return
+		}
+		policy = &created
+	}
+	err = assignBackupPolicy(volume.Id, policy.Id)
+	if err != nil {
+		sendErrorMessage(chatID, "分配备份策略失败: "+err.Error())
+		return
+	}
+
+	text = fmt.Sprintf("已为引导卷 '%s' 分配自定义备份策略 '%s'", *volume.DisplayName, *policy.DisplayName)
+	if count > freeTierBackupCount {
+		text += fmt.Sprintf("\n\n⚠️ 保留份数超过免费额度 (%d 个卷备份)，可能会产生费用", freeTierBackupCount)
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	bot.Send(msg)
+}
+
+// 检查备份数量和存储空间是否超出免费额度，未超出时返回空字符串。
+// 免费额度由引导卷、块存储卷及其备份共用
+func backupStorageWarning(backups []core.BootVolumeBackup) string {
+	var backupGBs int64
+	for _, backup := range backups {
+		backupGBs += getBackupSizeInGBs(backup)
+	}
+	backupCount := len(backups)
+	volumeBackups, _ := listBlockVolumeBackups()
+	for _, backup := range volumeBackups {
+		backupCount++
+		if backup.UniqueSizeInGBs != nil {
+			backupGBs += *backup.UniqueSizeInGBs
+		} else if backup.SizeInGBs != nil {
+			backupGBs += *backup.SizeInGBs
+		}
+	}
+	var volumeGBs int64
+	for _, volume := range getAllBootVolumes() {
+		if volume.SizeInGBs != nil {
+			volumeGBs += *volume.SizeInGBs
+		}
+	}
+	blockVolumes, _ := listBlockVolumes()
+	for _, volume := range blockVolumes {
+		if volume.SizeInGBs != nil {
+			volumeGBs += *volume.SizeInGBs
+		}
+	}
+
+	var warnings []string
+	if backupCount > freeTierBackupCount {
+		warnings = append(warnings, fmt.Sprintf("⚠️ 卷备份数量 %d 个，超过免费额度 %d 个", backupCount, freeTierBackupCount))
+	}
+	if volumeGBs+backupGBs > freeTierStorageGBs {
+		warnings = append(warnings, fmt.Sprintf("⚠️ 卷 %d GB + 备份 %d GB，超过免费额度 %d GB", volumeGBs, backupGBs, freeTierStorageGBs))
+	}
+	return strings.Join(warnings, "\n")
+}
+
+// 备份实际占用的存储空间，增量备份以 UniqueSizeInGBs 为准
+func getBackupSizeInGBs(backup core.BootVolumeBackup) int64 {
+	if backup.UniqueSizeInGBs != nil {
+		return *backup.UniqueSizeInGBs
+	}
+	if backup.SizeInGBs != nil {
+		return *backup.SizeInGBs
+	}
+	return 0
+}
+
+func getBootVolumeBackupState(state core.BootVolumeBackupLifecycleStateEnum) string {
+	var friendlyState string
+	switch state {
+	case core.BootVolumeBackupLifecycleStateCreating:
+		friendlyState = "正在创建"
+	case core.BootVolumeBackupLifecycleStateRequestReceived:
+		friendlyState = "已接收　"
+	case core.BootVolumeBackupLifecycleStateAvailable:
+		friendlyState = "可用　　"
+	case core.BootVolumeBackupLifecycleStateTerminating:
+		friendlyState = "正在删除"
+	case core.BootVolumeBackupLifecycleStateTerminated:
+		friendlyState = "已删除　"
+	case core.BootVolumeBackupLifecycleStateFaulty:
+		friendlyState = "故障　　"
+	default:
+		friendlyState = string(state)
+	}
+	return friendlyState
+}
+
+// 列出引导卷备份（不包括已删除的备份），bootVolumeId 为空时列出所有引导卷的备份
+func listBootVolumeBackups(bootVolumeId *string) ([]core.BootVolumeBackup, error) {
+	value, err := getCached(cacheKey("boot_volume_backups", stringValue(bootVolumeId)), func() (interface{}, error) {
+		var backups []core.BootVolumeBackup
+		for _, id := range listCompartmentIds {
+			req := core.ListBootVolumeBackupsRequest{
+				CompartmentId:   common.String(id),
+				BootVolumeId:    bootVolumeId,
+				SortBy:          core.ListBootVolumeBackupsSortByTimecreated,
+				SortOrder:       core.ListBootVolumeBackupsSortOrderDesc,
+				RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+			}
+			err := listAllPages(func(page *string) (*string, error) {
+				req.Page = page
+				resp, err := storageClient.ListBootVolumeBackups(ctx, req)
+				for _, backup := range resp.Items {
+					if backup.LifecycleState != core.BootVolumeBackupLifecycleStateTerminated {
+						backups = append(backups, backup)
+					}
+				}
+				return resp.OpcNextPage, err
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		return backups, nil
+	})
+	result, _ := value.([]core.BootVolumeBackup)
+	return result, err
+}
+
+// 根据按钮中的编号获取引导卷备份，已删除的备份返回错误
+func getBootVolumeBackupByKey(backupKey int) (core.BootVolumeBackup, error) {
+	id, err := lookupOcid(backupKey)
+	if err != nil {
+		return core.BootVolumeBackup{}, err
+	}
+	req := core.GetBootVolumeBackupRequest{
+		BootVolumeBackupId: id,
+		RequestMetadata:    getCustomRequestMetadataWithRetryPolicy(),
+	}
+	resp, err := storageClient.GetBootVolumeBackup(ctx, req)
+	if err == nil && (resp.LifecycleState == core.BootVolumeBackupLifecycleStateTerminating ||
+		resp.LifecycleState == core.BootVolumeBackupLifecycleStateTerminated) {
+		err = fmt.Errorf("备份 '%s' 已删除", *resp.DisplayName)
+	}
+	return resp.BootVolumeBackup, err
+}
+
+// 列出块存储卷备份（不包括已删除的备份）
+func listBlockVolumeBackups() ([]core.VolumeBackup, error) {
+	value, err := getCached(cacheKey("block_volume_backups"), func() (interface{}, error) {
+		var backups []core.VolumeBackup
+		for _, id := range listCompartmentIds {
+			req := core.ListVolumeBackupsRequest{
+				CompartmentId:   common.String(id),
+				RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+			}
+			err := listAllPages(func(page *string) (*string, error) {
+				req.Page = page
+				resp, err := storageClient.ListVolumeBackups(ctx, req)
+				for _, backup := range resp.Items {
+					if backup.LifecycleState != core.VolumeBackupLifecycleStateTerminated {
+						backups = append(backups, backup)
+					}
+				}
+				return resp.OpcNextPage, err
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		return backups, nil
+	})
+	result, _ := value.([]core.VolumeBackup)
+	return result, err
+}
+
+// 创建引导卷备份
+func createBootVolumeBackup(c core.BlockstorageClient, bootVolumeId *string, backupType core.CreateBootVolumeBackupDetailsTypeEnum, job *resourceJob) (core.BootVolumeBackup, error) {
+	req := core.CreateBootVolumeBackupRequest{
+		CreateBootVolumeBackupDetails: core.CreateBootVolumeBackupDetails{
+			BootVolumeId: bootVolumeId,
+			DisplayName:  common.String(time.Now().Format("backup-20060102-1504")),
+			Type:         backupType,
+			FreeformTags: job.freeformTags(),
+			DefinedTags:  job.definedTags(),
+		},
+		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+	}
+	resp, err := c.CreateBootVolumeBackup(ctx, req)
+	return resp.BootVolumeBackup, err
+}
+
+// 删除引导卷备份
+func deleteBootVolumeBackup(c core.BlockstorageClient, backupId *string) (*http.Response, error) {
+	req := core.DeleteBootVolumeBackupRequest{
+		BootVolumeBackupId: backupId,
+		RequestMetadata:    getCustomRequestMetadataWithRetryPolicy(),
+	}
+	resp, err := c.DeleteBootVolumeBackup(ctx, req)
+	return resp.RawResponse, err
+}
+
+// 从备份恢复到指定可用性域中的新引导卷
+func restoreBootVolumeBackup(backupId *string, availabilityDomain *string, job *resourceJob) (core.BootVolume, error) {
+	req := core.CreateBootVolumeRequest{
+		CreateBootVolumeDetails: core.CreateBootVolumeDetails{
+			CompartmentId:      getCompartmentId(),
+			AvailabilityDomain: availabilityDomain,
+			DisplayName:        common.String(time.Now().Format("restored-20060102-1504")),
+			SourceDetails:      core.BootVolumeSourceFromBootVolumeBackupDetails{Id: backupId},
+			FreeformTags:       job.freeformTags(),
+			DefinedTags:        job.definedTags(),
+		},
+		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+	}
+	resp, err := storageClient.CreateBootVolume(ctx, req)
+	return resp.BootVolume, err
+}
+
+// 列出 Oracle 预定义的备份策略 (bronze/silver/gold)
+func listOracleBackupPolicies() ([]core.VolumeBackupPolicy, error) {
+	req := core.ListVolumeBackupPoliciesRequest{
+		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+	}
+	var policies []core.VolumeBackupPolicy
+	err := listAllPages(func(page *string) (*string, error) {
+		req.Page = page
+		resp, err := storageClient.ListVolumeBackupPolicies(ctx, req)
+		policies = append(policies, resp.Items...)
+		return resp.OpcNextPage, err
+	})
+	return policies, err
+}
+
+func getVolumeBackupPolicy(policyId *string) (core.VolumeBackupPolicy, error) {
+	req := core.GetVolumeBackupPolicyRequest{
+		PolicyId:        policyId,
+		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+	}
+	resp, err := storageClient.GetVolumeBackupPolicy(ctx, req)
+	return resp.VolumeBackupPolicy, err
+}
+
+// 在当前区间中查找本工具创建的指定名称的备份策略，不存在时返回 nil
+func findVolumeBackupPolicy(displayName string) (*core.VolumeBackupPolicy, error) {
+	req := core.ListVolumeBackupPoliciesRequest{
+		CompartmentId:   getCompartmentId(),
+		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+	}
+	var found *core.VolumeBackupPolicy
+	err := listAllPages(func(page *string) (*string, error) {
+		req.Page = page
+		resp, err := storageClient.ListVolumeBackupPolicies(ctx, req)
+		for i, policy := range resp.Items {
+			if found == nil && *policy.DisplayName == displayName && policy.FreeformTags[tagCreatedByKey] == tagCreatedByValue {
+				found = &resp.Items[i]
+			}
+		}
+		return resp.OpcNextPage, err
+	})
+	return found, err
+}
+
+// 创建自定义备份策略
+func createVolumeBackupPolicy(displayName string, schedules []core.VolumeBackupSchedule, job *resourceJob) (core.VolumeBackupPolicy, error) {
+	req := core.CreateVolumeBackupPolicyRequest{
+		CreateVolumeBackupPolicyDetails: core.CreateVolumeBackupPolicyDetails{
+			CompartmentId: getCompartmentId(),
+			DisplayName:   common.String(displayName),
+			Schedules:     schedules,
+			FreeformTags:  job.freeformTags(),
+			DefinedTags:   job.definedTags(),
+		},
+		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+	}
+	resp, err := storageClient.CreateVolumeBackupPolicy(ctx, req)
+	return resp.VolumeBackupPolicy, err
+}
+
+// 获取卷当前的备份策略分配，未分配时返回 nil
+func getBackupPolicyAssignment(assetId *string) (*core.VolumeBackupPolicyAssignment, error) {
+	req := core.GetVolumeBackupPolicyAssetAssignmentRequest{
+		AssetId:         assetId,
+		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+	}
+	resp, err := storageClient.GetVolumeBackupPolicyAssetAssignment(ctx, req)
+	if err != nil || len(resp.Items) == 0 {
+		return nil, err
+	}
+	return &resp.Items[0], nil
+}
+
+// 为卷分配备份策略，已分配的策略会被替换
+func assignBackupPolicy(assetId, policyId *string) error {
+	req := core.CreateVolumeBackupPolicyAssignmentRequest{
+		CreateVolumeBackupPolicyAssignmentDetails: core.CreateVolumeBackupPolicyAssignmentDetails{
+			AssetId:  assetId,
+			PolicyId: policyId,
+		},
+		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+	}
+	_, err := storageClient.CreateVolumeBackupPolicyAssignment(ctx, req)
+	return err
+}
+
+// 取消分配备份策略
+func deleteBackupPolicyAssignment(assignmentId *string) (*http.Response, error) {
+	req := core.DeleteVolumeBackupPolicyAssignmentRequest{
+		PolicyAssignmentId: assignmentId,
+		RequestMetadata:    getCustomRequestMetadataWithRetryPolicy(),
+	}
+	resp, err := storageClient.DeleteVolumeBackupPolicyAssignment(ctx, req)
+	return resp.RawResponse, err
+}
@@ -0,0 +1,645 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+func manageStorageTelegram(chatID int64) {
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("引导卷", "account_action:manage_boot_volumes"),
+			tgbotapi.NewInlineKeyboardButtonData("块存储卷", "account_action:manage_block_volumes"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("引导卷备份", "account_action:boot_volume_backups"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("返回", "select_account:"+strconv.Itoa(getCurrentAccountIndex())),
+		),
+	)
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("存储管理 (当前账号: %s)\n请选择操作：", oracleSectionName))
+	msg.ReplyMarkup = keyboard
+	bot.Send(msg)
+}
+
+func manageBlockVolumesTelegram(chatID int64) {
+	msg := tgbotapi.NewMessage(chatID, "正在获取块存储卷数据...")
+	sentMsg, _ := bot.Send(msg)
+
+	volumes, err := listBlockVolumes()
+	if err != nil {
+		editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, "获取块存储卷失败: "+err.Error())
+		bot.Send(editMsg)
+		return
+	}
+
+	var messageText strings.Builder
+	messageText.WriteString(fmt.Sprintf("块存储卷 (当前账号: %s)\n\n", oracleSectionName))
+	if len(volumes) == 0 {
+		messageText.WriteString("没有找到任何块存储卷。\n")
+	} else {
+		messageText.WriteString(fmt.Sprintf("%-5s %-30s %-15s %-10s\n", "序号", "名称", "状态", "大小(GB)"))
+	}
+
+	indexes := getFilteredIndexes("block_volumes", blockVolumeListItems(volumes), &messageText)
+	if len(volumes) > 0 && len(indexes) == 0 {
+		messageText.WriteString("没有符合筛选条件的块存储卷\n")
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, i := range getPageIndexes("block_volumes", indexes) {
+		volume := volumes[i]
+		messageText.WriteString(fmt.Sprintf("%-5d %-30s %-15s %-10d\n",
+			i+1,
+			*volume.DisplayName,
+			getVolumeState(volume.LifecycleState),
+			*volume.SizeInGBs))
+		button := tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("块存储卷 %d", i+1),
+			fmt.Sprintf("block_volume_details:%d", ocidKey(volume.Id)))
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(button))
+	}
+
+	if row := pageButtons("block_volumes", len(indexes)); row != nil {
+		keyboard = append(keyboard, row)
+	}
+	keyboard = append(keyboard, listFilterButtons("block_volumes"))
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("创建块存储卷", "account_action:create_block_volume"),
+		tgbotapi.NewInlineKeyboardButtonData("返回", "account_action:manage_storage"),
+	))
+
+	editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, messageText.String())
+	editMsg.ReplyMarkup = &tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	bot.Send(editMsg)
+}
+
+// volumeKey 和 instanceKey 为 ocidKey 返回的编号，列表变化后仍然对应原来的块存储卷和实例
+func showBlockVolumeDetails(chatID int64, volumeKey int) {
+	volume, err := getBlockVolumeByKey(volumeKey)
+	if err != nil {
+		sendErrorMessage(chatID, "获取块存储卷失败: "+err.Error())
+		return
+	}
+
+	attachments, _ := listVolumeAttachments(volume.Id)
+	attachIns := make([]string, 0)
+	for _, attachment := range attachments {
+		attachType := "半虚拟化"
+		if _, ok := attachment.(core.IScsiVolumeAttachment); ok {
+			attachType = "iSCSI"
+		}
+		ins, err := getInstance(computeClient, attachment.GetInstanceId())
+		if err != nil {
+			attachIns = append(attachIns, err.Error())
+		} else {
+			attachIns = append(attachIns, fmt.Sprintf("%s (%s)", *ins.DisplayName, attachType))
+		}
+	}
+
+	var messageText strings.Builder
+	messageText.WriteString("块存储卷详情：\n\n")
+	messageText.WriteString(fmt.Sprintf("名称: %s\n", *volume.DisplayName))
+	messageText.WriteString(fmt.Sprintf("状态: %s\n", getVolumeState(volume.LifecycleState)))
+	messageText.WriteString(fmt.Sprintf("OCID: %s\n", *volume.Id))
+	messageText.WriteString(fmt.Sprintf("大小: %d GB\n", *volume.SizeInGBs))
+	messageText.WriteString(fmt.Sprintf("可用性域: %s\n", *volume.AvailabilityDomain))
+	messageText.WriteString(fmt.Sprintf("性能: %s\n", getVolumePerformance(volume.VpusPerGB)))
+	messageText.WriteString(fmt.Sprintf("附加的实例: %s\n", strings.Join(attachIns, ", ")))
+	messageText.WriteString(fmt.Sprintf("标签: %s\n", formatTags(volume.FreeformTags, volume.DefinedTags)))
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("修改性能", fmt.Sprintf("block_volume_action:%d:performance", volumeKey)),
+			tgbotapi.NewInlineKeyboardButtonData("修改大小", fmt.Sprintf("block_volume_action:%d:resize", volumeKey)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("附加到实例", fmt.Sprintf("block_volume_action:%d:attach", volumeKey)),
+			tgbotapi.NewInlineKeyboardButtonData("分离", fmt.Sprintf("block_volume_action:%d:detach", volumeKey)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("删除块存储卷", fmt.Sprintf("block_volume_action:%d:delete", volumeKey)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("返回块存储卷列表", "account_action:manage_block_volumes"),
+		),
+	)
+
+	msg := tgbotapi.NewMessage(chatID, messageText.String())
+	msg.ReplyMarkup = keyboard
+	bot.Send(msg)
+}
+
+func handleBlockVolumeAction(chatID int64, volumeKey int, action string) {
+	volume, err := getBlockVolumeByKey(volumeKey)
+	if err != nil {
+		sendErrorMessage(chatID, "获取块存储卷失败: "+err.Error())
+		return
+	}
+
+	switch action {
+	case "performance":
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("低成本", fmt.Sprintf("block_volume_performance:%d:0", volumeKey)),
+				tgbotapi.NewInlineKeyboardButtonData("均衡", fmt.Sprintf("block_volume_performance:%d:10", volumeKey)),
+				tgbotapi.NewInlineKeyboardButtonData("高性能", fmt.Sprintf("block_volume_performance:%d:20", volumeKey)),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("返回", fmt.Sprintf("block_volume_details:%d", volumeKey)),
+			),
+		)
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("当前块存储卷性能：%s\n请选择新的块存储卷性能：", getVolumePerformance(volume.VpusPerGB)))
+		msg.ReplyMarkup = keyboard
+		bot.Send(msg)
+	case "resize":
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("当前块存储卷大小：%d GB\n请输入新的块存储卷大小（GB）：", *volume.SizeInGBs))
+		msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, Selective: true}
+		bot.Send(msg)
+		setUserState(chatID, "resizing_block_volume", volumeKey)
+	case "attach":
+		selectBlockVolumeAttachInstance(chatID, volumeKey, volume)
+	case "detach":
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("确认分离", fmt.Sprintf("confirm_detach_block_volume:%d", volumeKey)),
+				tgbotapi.NewInlineKeyboardButtonData("取消", fmt.Sprintf("block_volume_details:%d", volumeKey)),
+			),
+		)
+		msg := tgbotapi.NewMessage(chatID, "确定要从所有实例分离此块存储卷吗？\niSCSI 附加的卷请先在实例中卸载并注销 iSCSI 会话。")
+		msg.ReplyMarkup = keyboard
+		bot.Send(msg)
+	case "delete":
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("确认删除", fmt.Sprintf("confirm_delete_block_volume:%d", volumeKey)),
+				tgbotapi.NewInlineKeyboardButtonData("取消", fmt.Sprintf("block_volume_details:%d", volumeKey)),
+			),
+		)
+		msg := tgbotapi.NewMessage(chatID, "确定要删除此块存储卷吗？此操作不可逆。")
+		msg.ReplyMarkup = keyboard
+		bot.Send(msg)
+	default:
+		sendErrorMessage(chatID, "未知的操作")
+	}
+}
+
+// 选择可用性域后，输入大小创建块存储卷
+func promptCreateBlockVolume(chatID int64) {
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for i, ad := range availabilityDomains {
+		button := tgbotapi.NewInlineKeyboardButtonData(*ad.Name, fmt.Sprintf("create_block_volume:%d", i))
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(button))
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("返回", "account_action:manage_block_volumes"),
+	))
+	msg := tgbotapi.NewMessage(chatID, "请选择块存储卷所在的可用性域（只能附加到同一可用性域中的实例）：")
+	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	bot.Send(msg)
+}
+
+func promptBlockVolumeSize(chatID int64, adIndex int) {
+	msg := tgbotapi.NewMessage(chatID, "请输入块存储卷大小（GB，50 - 32768）：")
+	msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, Selective: true}
+	bot.Send(msg)
+	setUserState(chatID, "creating_block_volume", adIndex)
+}
+
+func handleCreateBlockVolume(chatID int64, adIndex int, sizeText string) {
+	size, err := strconv.ParseInt(sizeText, 10, 64)
+	if err != nil || size < 50 {
+		sendErrorMessage(chatID, "输入的大小无效，请输入不小于 50 的整数")
+		return
+	}
+	if adIndex < 0 || adIndex >= len(availabilityDomains) {
+		sendErrorMessage(chatID, "无效的可用性域")
+		return
+	}
+	volume, err := createBlockVolume(availabilityDomains[adIndex].Name, size, newResourceJob())
+	if err != nil {
+		sendErrorMessage(chatID, "创建块存储卷失败: "+err.Error())
+	} else {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("正在创建块存储卷 '%s'，大小 %d GB", *volume.DisplayName, size))
+		bot.Send(msg)
+	}
+	manageBlockVolumesTelegram(chatID)
+}
+
+func handleResizeBlockVolume(chatID int64, volumeKey int, sizeText string) {
+	size, err := strconv.ParseInt(sizeText, 10, 64)
+	if err != nil {
+		sendErrorMessage(chatID, "输入的大小无效，请输入一个整数")
+		return
+	}
+	volume, err := getBlockVolumeByKey(volumeKey)
+	if err != nil {
+		sendErrorMessage(chatID, "获取块存储卷失败: "+err.Error())
+		return
+	}
+	_, err = updateBlockVolume(volume.Id, &size, nil)
+	if err != nil {
+		sendErrorMessage(chatID, "调整块存储卷大小失败: "+err.Error())
+	} else {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("块存储卷 '%s' 的大小已成功调整为 %d GB，请在实例中扩展分区和文件系统", *volume.DisplayName, size))
+		bot.Send(msg)
+	}
+	manageBlockVolumesTelegram(chatID)
+}
+
+func handleBlockVolumePerformance(chatID int64, volumeKey int, performance int64) {
+	volume, err := getBlockVolumeByKey(volumeKey)
+	if err != nil {
+		sendErrorMessage(chatID, "获取块存储卷失败: "+err.Error())
+		return
+	}
+	_, err = updateBlockVolume(volume.Id, nil, &performance)
+	if err != nil {
+		sendErrorMessage(chatID, "调整块存储卷性能失败: "+err.Error())
+	} else {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("块存储卷 '%s' 的性能已成功调整为 %d VPUs/GB", *volume.DisplayName, performance))
+		bot.Send(msg)
+	}
+	manageBlockVolumesTelegram(chatID)
+}
+
+func handleDeleteBlockVolume(chatID int64, volumeKey int) {
+	volume, err := getBlockVolumeByKey(volumeKey)
+	if err != nil {
+		sendErrorMessage(chatID, "获取块存储卷失败: "+err.Error())
+		return
+	}
+	_, err = deleteBlockVolume(volume.Id)
+	if err != nil {
+		sendErrorMessage(chatID, "删除块存储卷失败: "+err.Error())
+	} else {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("已成功删除块存储卷 '%s'", *volume.DisplayName))
+		bot.Send(msg)
+	}
+	manageBlockVolumesTelegram(chatID)
+}
+
+// 只列出与块存储卷位于同一可用性域的实例
+func selectBlockVolumeAttachInstance(chatID int64, volumeKey int, volume core.Volume) {
+	instances, err := ListInstances(ctx, computeClient)
+	if err != nil {
+		sendErrorMessage(chatID, "获取实例失败: "+err.Error())
+		return
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, ins := range instances {
+		if *ins.AvailabilityDomain != *volume.AvailabilityDomain {
+			continue
+		}
+		if ins.LifecycleState != core.InstanceLifecycleStateRunning && ins.LifecycleState != core.InstanceLifecycleStateStopped {
+			continue
+		}
+		button := tgbotapi.NewInlineKeyboardButtonData(*ins.DisplayName, fmt.Sprintf("attach_block_volume_instance:%d:%d", volumeKey, ocidKey(ins.Id)))
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(button))
+	}
+	if len(keyboard) == 0 {
+		sendErrorMessage(chatID, "可用性域 "+*volume.AvailabilityDomain+" 中没有可附加的实例")
+		return
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("返回", fmt.Sprintf("block_volume_details:%d", volumeKey)),
+	))
+	msg := tgbotapi.NewMessage(chatID, "请选择要附加到的实例：")
+	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	bot.Send(msg)
+}
+
+func selectBlockVolumeAttachType(chatID int64, volumeKey, instanceKey int) {
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("半虚拟化", fmt.Sprintf("attach_block_volume:%d:%d:pv", volumeKey, instanceKey)),
+			tgbotapi.NewInlineKeyboardButtonData("iSCSI", fmt.Sprintf("attach_block_volume:%d:%d:iscsi", volumeKey, instanceKey)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("返回", fmt.Sprintf("block_volume_details:%d", volumeKey)),
+		),
+	)
+	msg := tgbotapi.NewMessage(chatID, "请选择附加类型：")
+	msg.ReplyMarkup = keyboard
+	bot.Send(msg)
+}
+
+func handleAttachBlockVolume(chatID int64, volumeKey, instanceKey int, attachType string) {
+	volume, err := getBlockVolumeByKey(volumeKey)
+	if err != nil {
+		sendErrorMessage(chatID, "获取块存储卷失败: "+err.Error())
+		return
+	}
+	ins, err := getInstanceByKey(instanceKey)
+	if err != nil {
+		sendErrorMessage(chatID, "获取实例信息失败: "+err.Error())
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, "正在附加块存储卷，请稍候...")
+	sentMsg, _ := bot.Send(msg)
+	progress := func(text string) {
+		editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, fmt.Sprintf("附加块存储卷 '%s' 到实例 '%s'\n\n%s", *volume.DisplayName, *ins.DisplayName, text))
+		bot.Send(editMsg)
+	}
+
+	attachment, err := attachBlockVolume(ins.Id, volume.Id, attachType == "iscsi")
+	if err != nil {
+		progress("❌ 附加块存储卷失败: " + err.Error())
+		return
+	}
+
+	// 等待附加完成最多需要两分钟，在后台执行，避免阻塞其他操作
+	s := currentSession()
+	go func() {
+		attachment, err := waitVolumeAttached(s.compute, attachment.GetId(), progress)
+		if err != nil {
+			progress("❌ 获取附加状态失败: " + err.Error())
+			return
+		}
+
+		text := fmt.Sprintf("块存储卷 '%s' 已附加到实例 '%s'", *volume.DisplayName, *ins.DisplayName)
+		if iscsi, ok := attachment.(core.IScsiVolumeAttachment); ok {
+			text += "\n\n请在实例中执行以下命令连接 iSCSI 卷：\n" + iscsiAttachCommands(iscsi)
+		}
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("返回块存储卷列表", "account_action:manage_block_volumes"),
+			),
+		)
+		editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, text)
+		editMsg.ReplyMarkup = &keyboard
+		bot.Send(editMsg)
+	}()
+}
+
+func handleDetachBlockVolume(chatID int64, volumeKey int) {
+	volume, err := getBlockVolumeByKey(volumeKey)
+	if err != nil {
+		sendErrorMessage(chatID, "获取块存储卷失败: "+err.Error())
+		return
+	}
+
+	attachments, err := listVolumeAttachments(volume.Id)
+	if err != nil {
+		sendErrorMessage(chatID, "获取块存储卷附件失败: "+err.Error())
+		return
+	}
+	if len(attachments) == 0 {
+		sendErrorMessage(chatID, "该块存储卷未附加到任何实例")
+		return
+	}
+
+	for _, attachment := range attachments {
+		_, err := detachBlockVolume(attachment.GetId())
+		if err != nil {
+			sendErrorMessage(chatID, "分离块存储卷失败: "+err.Error())
+			continue
+		}
+		text := fmt.Sprintf("正在分离块存储卷 '%s'", *volume.DisplayName)
+		if iscsi, ok := attachment.(core.IScsiVolumeAttachment); ok {
+			text += "\n\n如未注销 iSCSI 会话，请在实例中执行：\n" + iscsiDetachCommands(iscsi)
+		}
+		msg := tgbotapi.NewMessage(chatID, text)
+		bot.Send(msg)
+	}
+	manageBlockVolumesTelegram(chatID)
+}
+
+// 连接 iSCSI 卷的命令
+func iscsiAttachCommands(a core.IScsiVolumeAttachment) string {
+	target := fmt.Sprintf("%s:%d", *a.Ipv4, *a.Port)
+	return fmt.Sprintf("sudo iscsiadm -m node -o new -T %s -p %s\n"+
+		"sudo iscsiadm -m node -o update -T %s -n node.startup -v automatic\n"+
+		"sudo iscsiadm -m node -T %s -p %s -l",
+		*a.Iqn, target, *a.Iqn, *a.Iqn, target)
+}
+
+// 断开 iSCSI 卷的命令
+func iscsiDetachCommands(a core.IScsiVolumeAttachment) string {
+	target := fmt.Sprintf("%s:%d", *a.Ipv4, *a.Port)
+	return fmt.Sprintf("sudo iscsiadm -m node -T %s -p %s -u\n"+
+		"sudo iscsiadm -m node -o delete -T %s -p %s",
+		*a.Iqn, target, *a.Iqn, target)
+}
+
+func getVolumePerformance(vpusPerGB *int64) string {
+	if vpusPerGB == nil {
+		return "-"
+	}
+	switch *vpusPerGB {
+	case 0:
+		return fmt.Sprintf("低成本 (VPU:%d)", *vpusPerGB)
+	case 10:
+		return fmt.Sprintf("均衡 (VPU:%d)", *vpusPerGB)
+	case 20:
+		return fmt.Sprintf("性能较高 (VPU:%d)", *vpusPerGB)
+	default:
+		return fmt.Sprintf("UHP (VPU:%d)", *vpusPerGB)
+	}
+}
+
+func getVolumeState(state core.VolumeLifecycleStateEnum) string {
+	var friendlyState string
+	switch state {
+	case core.VolumeLifecycleStateProvisioning:
+		friendlyState = "正在预配"
+	case core.VolumeLifecycleStateRestoring:
+		friendlyState = "正在恢复"
+	case core.VolumeLifecycleStateAvailable:
+		friendlyState = "可用　　"
+	case core.VolumeLifecycleStateTerminating:
+		friendlyState = "正在终止"
+	case core.VolumeLifecycleStateTerminated:
+		friendlyState = "已终止　"
+	case core.VolumeLifecycleStateFaulty:
+		friendlyState = "故障　　"
+	default:
+		friendlyState = string(state)
+	}
+	return friendlyState
+}
+
+// 列出块存储卷（不包括已终止的卷）
+func listBlockVolumes() ([]core.Volume, error) {
+	value, err := getCached(cacheKey("block_volumes"), func() (interface{}, error) {
+		var volumes []core.Volume
+		for _, id := range listCompartmentIds {
+			req := core.ListVolumesRequest{
+				CompartmentId:   common.String(id),
+				RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+			}
+			err := listAllPages(func(page *string) (*string, error) {
+				req.Page = page
+				resp, err := storageClient.ListVolumes(ctx, req)
+				for _, volume := range resp.Items {
+					if volume.LifecycleState != core.VolumeLifecycleStateTerminated {
+						volumes = append(volumes, volume)
+					}
+				}
+				return resp.OpcNextPage, err
+			})
+			if err != nil {
+				return nil, fmt.Errorf("获取块存储卷列表失败: %v", err)
+			}
+		}
+		return volumes, nil
+	})
+	result, _ := value.([]core.Volume)
+	return result, err
+}
+
+// 根据按钮中的编号获取块存储卷，已删除的块存储卷返回错误
+func getBlockVolumeByKey(volumeKey int) (core.Volume, error) {
+	id, err := lookupOcid(volumeKey)
+	if err != nil {
+		return core.Volume{}, err
+	}
+	req := core.GetVolumeRequest{
+		VolumeId:        id,
+		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+	}
+	resp, err := storageClient.GetVolume(ctx, req)
+	if err == nil && (resp.LifecycleState == core.VolumeLifecycleStateTerminating ||
+		resp.LifecycleState == core.VolumeLifecycleStateTerminated) {
+		err = fmt.Errorf("块存储卷 '%s' 已删除", *resp.DisplayName)
+	}
+	return resp.Volume, err
+}
+
+// 创建块存储卷
+func createBlockVolume(availabilityDomain *string, sizeInGBs int64, job *resourceJob) (core.Volume, error) {
+	req := core.CreateVolumeRequest{
+		CreateVolumeDetails: core.CreateVolumeDetails{
+			CompartmentId:      getCompartmentId(),
+			AvailabilityDomain: availabilityDomain,
+			DisplayName:        common.String(time.Now().Format("volume-20060102-1504")),
+			SizeInGBs:          common.Int64(sizeInGBs),
+			FreeformTags:       job.freeformTags(),
+			DefinedTags:        job.definedTags(),
+		},
+		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+	}
+	resp, err := storageClient.CreateVolume(ctx, req)
+	return resp.Volume, err
+}
+
+// 更新块存储卷
+func updateBlockVolume(volumeId *string, sizeInGBs *int64, vpusPerGB *int64) (core.Volume, error) {
+	details := core.UpdateVolumeDetails{}
+	if sizeInGBs != nil {
+		details.SizeInGBs = sizeInGBs
+	}
+	if vpusPerGB != nil {
+		details.VpusPerGB = vpusPerGB
+	}
+	req := core.UpdateVolumeRequest{
+		VolumeId:            volumeId,
+		UpdateVolumeDetails: details,
+		RequestMetadata:     getCustomRequestMetadataWithRetryPolicy(),
+	}
+	resp, err := storageClient.UpdateVolume(ctx, req)
+	return resp.Volume, err
+}
+
+// 删除块存储卷
+func deleteBlockVolume(volumeId *string) (*http.Response, error) {
+	req := core.DeleteVolumeRequest{
+		VolumeId:        volumeId,
+		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+	}
+	resp, err := storageClient.DeleteVolume(ctx, req)
+	return resp.RawResponse, err
+}
+
+// 附加块存储卷，iscsi 为 false 时使用半虚拟化方式附加
+func attachBlockVolume(instanceId, volumeId *string, iscsi bool) (core.VolumeAttachment, error) {
+	var details core.AttachVolumeDetails
+	if iscsi {
+		details = core.AttachIScsiVolumeDetails{InstanceId: instanceId, VolumeId: volumeId}
+	} else {
+		details = core.AttachParavirtualizedVolumeDetails{InstanceId: instanceId, VolumeId: volumeId}
+	}
+	req := core.AttachVolumeRequest{
+		AttachVolumeDetails: details,
+		RequestMetadata:     getCustomRequestMetadataWithRetryPolicy(),
+	}
+	resp, err := computeClient.AttachVolume(ctx, req)
+	return resp.VolumeAttachment, err
+}
+
+// 等待块存储卷附加完成，iSCSI 附加完成后才能获取到 IQN 等连接信息。状态变化时调用 progress
+func waitVolumeAttached(c core.ComputeClient, attachmentId *string, progress func(string)) (attachment core.VolumeAttachment, err error) {
+	var state core.VolumeAttachmentLifecycleStateEnum
+	for i := 0; i < 40; i++ {
+		req := core.GetVolumeAttachmentRequest{
+			VolumeAttachmentId: attachmentId,
+			RequestMetadata:    getCustomRequestMetadataWithRetryPolicy(),
+		}
+		var resp core.GetVolumeAttachmentResponse
+		resp, err = c.GetVolumeAttachment(ctx, req)
+		if err != nil {
+			return
+		}
+		attachment = resp.VolumeAttachment
+		if attachment.GetLifecycleState() == core.VolumeAttachmentLifecycleStateAttached {
+			return
+		}
+		if attachment.GetLifecycleState() != state {
+			state = attachment.GetLifecycleState()
+			progress(fmt.Sprintf("正在等待附加完成，当前状态: %s", state))
+		}
+		time.Sleep(3 * time.Second)
+	}
+	err = fmt.Errorf("等待附加超时, 当前状态: %s", attachment.GetLifecycleState())
+	return
+}
+
+// 分离块存储卷
+func detachBlockVolume(attachmentId *string) (*http.Response, error) {
+	req := core.DetachVolumeRequest{
+		VolumeAttachmentId: attachmentId,
+		RequestMetadata:    getCustomRequestMetadataWithRetryPolicy(),
+	}
+	resp, err := computeClient.DetachVolume(ctx, req)
+	return resp.RawResponse, err
+}
+
+// 获取块存储卷附件（不包括已分离的附件）
+func listVolumeAttachments(volumeId *string) ([]core.VolumeAttachment, error) {
+	value, err := getCached(cacheKey("volume_attachments", stringValue(volumeId)), func() (interface{}, error) {
+		var attachments []core.VolumeAttachment
+		for _, id := range listCompartmentIds {
+			req := core.ListVolumeAttachmentsRequest{
+				CompartmentId:   common.String(id),
+				VolumeId:        volumeId,
+				RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+			}
+			err := listAllPages(func(page *string) (*string, error) {
+				req.Page = page
+				resp, err := computeClient.ListVolumeAttachments(ctx, req)
+				for _, attachment := range resp.Items {
+					if attachment.GetLifecycleState() != core.VolumeAttachmentLifecycleStateDetached {
+						attachments = append(attachments, attachment)
+					}
+				}
+				return resp.OpcNextPage, err
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		return attachments, nil
+	})
+	result, _ := value.([]core.VolumeAttachment)
+	return result, err
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-ini/ini"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/oracle/oci-go-sdk/v65/usageapi"
+)
+
+// 已发送的费用预警记录，避免同一预警在同一周期内重复发送
+const budgetAlertsFileName = "budget-alerts.json"
+
+var (
+	budgetAlerted = make(map[string]bool)
+	budgetMutex   sync.Mutex
+)
+
+// 读取费用预警配置，账号中的 budget 会覆盖全局设置
+func loadBudgetConfig(c *appConfig, defSec *ini.Section) {
+	c.budgetInterval = defSec.Key("budgetInterval").MustInt(0)
+	c.budgetThreshold = defSec.Key("budget").MustFloat64(0)
+	c.budgetChargeAlert = defSec.Key("budgetChargeAlert").MustBool(true)
+}
+
+// 后台定期检查每个账号的本月成本，重新加载配置后使用新的检查间隔和账号列表
+func runBudgetChecker() {
+	loadBudgetAlerts()
+	for {
+		c := getConfig()
+		if c.budgetInterval <= 0 {
+			time.Sleep(time.Minute)
+			continue
+		}
+		for _, sec := range c.oracleSections {
+			if err := checkAccountBudget(c, sec); err != nil {
+				printlnErr(fmt.Sprintf("检查账号 [%s] 费用失败", sec.Name()), err.Error())
+			}
+		}
+		time.Sleep(time.Duration(c.budgetInterval) * time.Minute)
+	}
+}
+
+func checkAccountBudget(c *appConfig, sec *ini.Section) error {
+	var o Oracle
+	if err := sec.MapTo(&o); err != nil {
+		return err
+	}
+	p, err := getProvider(o)
+	if err != nil {
+		return err
+	}
+	threshold := c.budgetThreshold
+	if sec.HasKey("budget") {
+		threshold = sec.Key("budget").MustFloat64(0)
+	}
+
+	start, end := currMouthFirstLastDay()
+	report, err := getCostReport(p, start, end, usageapi.RequestSummarizedUsagesDetailsGranularityMonthly, "service")
+	if err != nil {
+		return err
+	}
+	period := start.Format("200601")
+
+	var keys, alerts []string
+	if threshold > 0 && float64(report.Total) > threshold {
+		key := budgetAlertKey(period, sec.Name(), "total")
+		if !isBudgetAlerted(key) {
+			keys = append(keys, key)
+			alerts = append(alerts, fmt.Sprintf("本月累计成本 %.2f %s 已超过预警阈值 %.2f", report.Total, report.Currency, threshold))
+		}
+	}
+	if c.budgetChargeAlert {
+		for _, item := range report.Items {
+			// 忽略四舍五入后为 0 的费用
+			if item.Amount < 0.01 {
+				continue
+			}
+			key := budgetAlertKey(period, sec.Name(), "service:"+item.Group)
+			if !isBudgetAlerted(key) {
+				keys = append(keys, key)
+				alerts = append(alerts, fmt.Sprintf("服务 %s 产生费用 %.2f %s", item.Group, item.Amount, item.Currency))
+			}
+		}
+	}
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	// 服务名称和账号名称中可能包含 Markdown 特殊字符 (如下划线)
+	text := fmt.Sprintf("⚠️ 费用预警 (%s)\n%s\n本月累计成本: %.2f %s", start.Format("2006-01"), strings.Join(alerts, "\n"), report.Total, report.Currency)
+	text = tgbotapi.EscapeText(tgbotapi.ModeMarkdown, text)
+	name := tgbotapi.EscapeText(tgbotapi.ModeMarkdown, sec.Name())
+	// 发送成功后才记录，发送失败时下次检查会重新发送
+	if _, err := sendMessage(name, text); err != nil {
+		return fmt.Errorf("发送费用预警失败: %v", err)
+	}
+	markBudgetAlerted(period, keys)
+	return nil
+}
+
+func budgetAlertKey(period, account, kind string) string {
+	return period + "|" + account + "|" + kind
+}
+
+func isBudgetAlerted(key string) bool {
+	budgetMutex.Lock()
+	defer budgetMutex.Unlock()
+	return budgetAlerted[key]
+}
+
+// 记录已发送的预警并清理之前周期的记录
+func markBudgetAlerted(period string, keys []string) {
+	budgetMutex.Lock()
+	defer budgetMutex.Unlock()
+	for key := range budgetAlerted {
+		if !strings.HasPrefix(key, period+"|") {
+			delete(budgetAlerted, key)
+		}
+	}
+	for _, key := range keys {
+		budgetAlerted[key] = true
+	}
+	data, err := json.MarshalIndent(budgetAlerted, "", "  ")
+	if err == nil {
+		err = ioutil.WriteFile(getBudgetAlertsFilePath(), data, 0644)
+	}
+	if err != nil {
+		printlnErr("保存费用预警记录失败", err.Error())
+	}
+}
+
+func loadBudgetAlerts() {
+	budgetMutex.Lock()
+	defer budgetMutex.Unlock()
+	data, err := ioutil.ReadFile(getBudgetAlertsFilePath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			printlnErr("读取费用预警记录失败", err.Error())
+		}
+		return
+	}
+	if err := json.Unmarshal(data, &budgetAlerted); err != nil {
+		printlnErr("解析费用预警记录失败", err.Error())
+	}
+}
+
+func getBudgetAlertsFilePath() string {
+	return filepath.Join(filepath.Dir(configFilePath), budgetAlertsFileName)
+}
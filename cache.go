@@ -0,0 +1,99 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/oracle/oci-go-sdk/v65/common"
+)
+
+// 列表查询结果的缓存时间。通过本程序修改资源后会清除当前账号和区域的缓存，
+// 在控制台等其他地方修改的资源最多延迟 cacheTTL 后显示，也可以在列表中点击「刷新」。
+const cacheTTL = 30 * time.Second
+
+type cacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+var (
+	cacheMutex   sync.Mutex
+	cacheEntries = make(map[string]cacheEntry)
+)
+
+// 缓存键以账号和区域开头，切换账号或区域后不会使用其他账号或区域的数据
+func cachePrefix(account, region string) string {
+	return account + "|" + region + "|"
+}
+
+// 缓存键还包含当前区间，切换区间后重新查询
+func cacheKey(kind string, args ...string) string {
+	return cachePrefix(oracleSectionName, oracle.Region) +
+		strings.Join(append([]string{compartmentId, strconv.FormatBool(compartmentRecursive), kind}, args...), "|")
+}
+
+// 返回缓存的结果，没有缓存或已过期时调用 load 查询。查询失败时不缓存
+func getCached(key string, load func() (interface{}, error)) (interface{}, error) {
+	cacheMutex.Lock()
+	entry, ok := cacheEntries[key]
+	cacheMutex.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.value, nil
+	}
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+	cacheMutex.Lock()
+	cacheEntries[key] = cacheEntry{value: value, expires: time.Now().Add(cacheTTL)}
+	cacheMutex.Unlock()
+	return value, nil
+}
+
+// 清除指定账号和区域的缓存
+func invalidateCache(account, region string) {
+	prefix := cachePrefix(account, region)
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	for key := range cacheEntries {
+		if strings.HasPrefix(key, prefix) {
+			delete(cacheEntries, key)
+		}
+	}
+}
+
+// 客户端发送修改资源的请求 (非 GET 请求) 时，清除该客户端所属账号和区域的缓存
+func setCacheInterceptor(client *common.BaseClient) {
+	account, region := oracleSectionName, oracle.Region
+	previous := client.Interceptor
+	client.Interceptor = func(request *http.Request) error {
+		if request.Method != http.MethodGet && request.Method != http.MethodHead {
+			invalidateCache(account, region)
+		}
+		if previous != nil {
+			return previous(request)
+		}
+		return nil
+	}
+}
+
+func refreshButton(target string) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData("刷新", "list_refresh:"+target)
+}
+
+// 清除当前账号和区域的缓存后重新显示列表
+func refreshList(chatID int64, target string) {
+	invalidateCache(oracleSectionName, oracle.Region)
+	showListTarget(chatID, target)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"crypto/md5"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/go-ini/ini"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+	"github.com/oracle/oci-go-sdk/v65/ospgateway"
+)
+
+type AccountCheck struct {
+	Name       string
+	Region     string
+	Tenancy    string
+	HomeRegion string
+	Regions    []string
+	PlanType   string
+	Err        error
+}
+
+// 检查所有账号的凭据，返回的结果与 oracleSections 顺序一致
+func checkAccounts() []AccountCheck {
+	sections := getConfig().oracleSections
+	checks := make([]AccountCheck, len(sections))
+	var wg sync.WaitGroup
+	for i, sec := range sections {
+		wg.Add(1)
+		go func(i int, sec *ini.Section) {
+			defer wg.Done()
+			checks[i] = checkAccount(sec)
+		}(i, sec)
+	}
+	wg.Wait()
+	return checks
+}
+
+// 检查账号凭据: 加载凭据并校验私钥指纹，获取租户信息和已订阅的区域，判断账号类型
+func checkAccount(sec *ini.Section) (c AccountCheck) {
+	c.Name = sec.Name()
+	var o Oracle
+	if c.Err = sec.MapTo(&o); c.Err != nil {
+		return
+	}
+	c.Region = o.Region
+
+	p, err := getProvider(o)
+	if err != nil {
+		c.Err = fmt.Errorf("加载凭据失败: %v", err)
+		return
+	}
+	completeOracle(&o, p)
+	c.Region = o.Region
+
+	// 实例主体和会话令牌没有固定的 API 密钥指纹
+	if o.Auth == "" || o.Auth == authApiKey {
+		key, err := p.PrivateRSAKey()
+		if err != nil {
+			c.Err = fmt.Errorf("解析私钥失败: %v", err)
+			return
+		}
+		fingerprint, err := getKeyFingerprint(&key.PublicKey)
+		if err != nil {
+			c.Err = fmt.Errorf("计算私钥指纹失败: %v", err)
+			return
+		}
+		if !strings.EqualFold(fingerprint, strings.TrimSpace(o.Fingerprint)) {
+			c.Err = fmt.Errorf("私钥与指纹不匹配, 私钥指纹为 %s", fingerprint)
+			return
+		}
+	}
+
+	client, err := identity.NewIdentityClientWithConfigurationProvider(p)
+	if err != nil {
+		c.Err = err
+		return
+	}
+	setProxyOrNot(&client.BaseClient)
+	setRateLimitInterceptor(&client.BaseClient, o.Tenancy)
+	tenancyResp, err := client.GetTenancy(ctx, identity.GetTenancyRequest{
+		TenancyId:       common.String(o.Tenancy),
+		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+	})
+	if err != nil {
+		c.Err = fmt.Errorf("获取租户信息失败: %v", err)
+		return
+	}
+	if tenancyResp.Name != nil {
+		c.Tenancy = *tenancyResp.Name
+	}
+
+	regionsResp, err := client.ListRegionSubscriptions(ctx, identity.ListRegionSubscriptionsRequest{
+		TenancyId:       common.String(o.Tenancy),
+		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+	})
+	if err != nil {
+		c.Err = fmt.Errorf("获取已订阅区域失败: %v", err)
+		return
+	}
+	for _, r := range regionsResp.Items {
+		c.Regions = append(c.Regions, *r.RegionName)
+		if r.IsHomeRegion != nil && *r.IsHomeRegion {
+			c.HomeRegion = *r.RegionName
+		}
+	}
+
+	// 账号类型查询失败不影响凭据检查结果
+	c.PlanType = getPlanType(p, o.Tenancy, c.HomeRegion)
+	return
+}
+
+// 计算公钥指纹，格式与 OCI 控制台显示的一致
+func getKeyFingerprint(key *rsa.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+	sum := md5.Sum(der)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02x", b)
+	}
+	return strings.Join(parts, ":"), nil
+}
+
+// 通过订阅信息判断账号是免费账号(Free Tier)还是升级后的账号(PAYG)
+func getPlanType(p common.ConfigurationProvider, tenancy, homeRegion string) string {
+	if homeRegion == "" {
+		return "未知"
+	}
+	client, err := ospgateway.NewSubscriptionServiceClientWithConfigurationProvider(p)
+	if err != nil {
+		return "未知"
+	}
+	setProxyOrNot(&client.BaseClient)
+	setRateLimitInterceptor(&client.BaseClient, tenancy)
+	client.SetRegion(homeRegion)
+	resp, err := client.ListSubscriptions(ctx, ospgateway.ListSubscriptionsRequest{
+		OspHomeRegion:   common.String(homeRegion),
+		CompartmentId:   common.String(tenancy),
+		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+	})
+	if err != nil || len(resp.Items) == 0 {
+		return "未知"
+	}
+	switch resp.Items[0].PlanType {
+	case ospgateway.SubscriptionSummaryPlanTypeFreeTier:
+		return "Free Tier"
+	case ospgateway.SubscriptionSummaryPlanTypePayg:
+		return "PAYG"
+	default:
+		return string(resp.Items[0].PlanType)
+	}
+}
+
+func printAccountChecks(checks []AccountCheck) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "账号\t状态\t区域\t租户\t类型\t已订阅区域")
+	for _, c := range checks {
+		if c.Err != nil {
+			fmt.Fprintf(w, "%s\t❌\t%s\t\t\t%s\n", c.Name, c.Region, c.Err.Error())
+			continue
+		}
+		fmt.Fprintf(w, "%s\t✅\t%s\t%s\t%s\t%s\n", c.Name, c.Region, c.Tenancy, c.PlanType, strings.Join(c.Regions, ","))
+	}
+	w.Flush()
+}
+
+// 命令行检查所有账号，存在检查失败的账号时以状态码 1 退出
+func runCheckCommand() {
+	checks := checkAccounts()
+	printAccountChecks(checks)
+	for _, c := range checks {
+		if c.Err != nil {
+			os.Exit(1)
+		}
+	}
+}
+
+// 启动时检查所有账号，检查失败的账号通过 Telegram 提醒
+func startupCheck() {
+	checks := checkAccounts()
+	printAccountChecks(checks)
+	var failed []string
+	for _, c := range checks {
+		if c.Err != nil {
+			// 错误信息中可能包含 Markdown 特殊字符
+			failed = append(failed, tgbotapi.EscapeText(tgbotapi.ModeMarkdown, fmt.Sprintf("[%s] %s", c.Name, c.Err.Error())))
+		}
+	}
+	if len(failed) > 0 {
+		sendMessage("", "以下账号检查失败:\n"+strings.Join(failed, "\n"))
+	}
+}
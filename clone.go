@@ -0,0 +1,260 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// 选择克隆目标可用性域
+func selectBootVolumeCloneTarget(chatID int64, volumeIndex int, volume core.BootVolume) {
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for i, ad := range availabilityDomains {
+		text := *ad.Name
+		if *ad.Name == *volume.AvailabilityDomain {
+			text += " (当前)"
+		}
+		button := tgbotapi.NewInlineKeyboardButtonData(text, fmt.Sprintf("clone_boot_volume:%d:%d", volumeIndex, i))
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(button))
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("返回", fmt.Sprintf("boot_volume_details:%d", volumeIndex)),
+	))
+	msg := tgbotapi.NewMessage(chatID, "请选择克隆到的可用性域：\n同一可用性域直接克隆，其他可用性域将通过备份和恢复完成，耗时较长。")
+	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	bot.Send(msg)
+}
+
+func handleCloneBootVolume(chatID int64, volumeIndex, adIndex int) {
+	bootVolumes := getAllBootVolumes()
+	if volumeIndex < 0 || volumeIndex >= len(bootVolumes) {
+		sendErrorMessage(chatID, "无效的引导卷索引")
+		return
+	}
+	if adIndex < 0 || adIndex >= len(availabilityDomains) {
+		sendErrorMessage(chatID, "无效的可用性域")
+		return
+	}
+	volume := bootVolumes[volumeIndex]
+	targetAd := availabilityDomains[adIndex].Name
+	// 克隆最长需要几十分钟，使用开始时的账号和客户端，期间切换账号、区域或区间不影响克隆
+	s := currentSession()
+
+	msg := tgbotapi.NewMessage(chatID, "正在克隆引导卷...")
+	sentMsg, _ := bot.Send(msg)
+	startTime := time.Now()
+	progress := func(text string) {
+		editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID,
+			fmt.Sprintf("克隆引导卷 '%s' 到 %s\n\n%s\n耗时: %s", *volume.DisplayName, *targetAd, text, fmtDuration(time.Since(startTime))))
+		bot.Send(editMsg)
+	}
+
+	// 克隆中创建的临时备份和新引导卷使用同一个任务 ID
+	job := newResourceJob()
+	// 跨可用性域克隆需要等待备份和恢复完成，在后台执行
+	go func() {
+		clone, err := cloneBootVolume(s, volume, targetAd, job, progress)
+		if err != nil {
+			progress("❌ 克隆引导卷失败: " + err.Error())
+			return
+		}
+
+		text := fmt.Sprintf("克隆引导卷 '%s' 到 %s\n\n✅ 克隆完成: %s\n耗时: %s", *volume.DisplayName, *targetAd, *clone.DisplayName, fmtDuration(time.Since(startTime)))
+		editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, text)
+		setCloneResult(sentMsg.MessageID, cloneResult{session: s, volumeId: *clone.Id})
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("使用新引导卷创建实例", fmt.Sprintf("clone_result:%d:launch", sentMsg.MessageID)),
+				tgbotapi.NewInlineKeyboardButtonData("查看引导卷", fmt.Sprintf("clone_result:%d:details", sentMsg.MessageID)),
+			),
+		)
+		editMsg.ReplyMarkup = &keyboard
+		bot.Send(editMsg)
+	}()
+}
+
+// 克隆完成的引导卷。OCID 超过 Telegram 回调数据的长度限制，
+// 按钮中使用进度消息的 ID，点击时再按 OCID 在当前列表中查找引导卷。
+type cloneResult struct {
+	session  *session
+	volumeId string
+	created  time.Time
+}
+
+// 克隆结果的保留时间，过期后按钮失效
+const cloneResultTTL = 24 * time.Hour
+
+var (
+	cloneResults      = make(map[int]cloneResult)
+	cloneResultsMutex sync.Mutex
+)
+
+// 保存克隆结果，同时清除过期的克隆结果
+func setCloneResult(key int, result cloneResult) {
+	cloneResultsMutex.Lock()
+	defer cloneResultsMutex.Unlock()
+	for k, r := range cloneResults {
+		if time.Since(r.created) > cloneResultTTL {
+			delete(cloneResults, k)
+		}
+	}
+	result.created = time.Now()
+	cloneResults[key] = result
+}
+
+func handleCloneResult(chatID int64, key int, action string) {
+	cloneResultsMutex.Lock()
+	result, ok := cloneResults[key]
+	if ok && time.Since(result.created) > cloneResultTTL {
+		delete(cloneResults, key)
+		ok = false
+	}
+	cloneResultsMutex.Unlock()
+	if !ok {
+		sendErrorMessage(chatID, "克隆结果已过期，请在引导卷列表中查看")
+		return
+	}
+	if !result.session.isCurrent() {
+		sendErrorMessage(chatID, fmt.Sprintf("新引导卷属于账号 [%s] 的区域 %s，请先切换到该账号和区域", result.session.name, result.session.oracle.Region))
+		return
+	}
+	volumeIndex := -1
+	for i, v := range getAllBootVolumes() {
+		if *v.Id == result.volumeId {
+			volumeIndex = i
+			break
+		}
+	}
+	if volumeIndex < 0 {
+		sendErrorMessage(chatID, "当前区间中未找到新引导卷，请切换到引导卷所在的区间")
+		return
+	}
+	switch action {
+	case "launch":
+		handleBootVolumeAction(chatID, volumeIndex, "launch")
+	case "details":
+		showBootVolumeDetails(chatID, volumeIndex)
+	}
+}
+
+// 克隆引导卷。同一可用性域直接克隆；其他可用性域先创建完整备份，再恢复到目标可用性域，完成后删除临时备份。
+// 等待恢复超时时新引导卷可能仍在从备份恢复，此时保留临时备份并在错误信息中返回备份的 OCID。
+func cloneBootVolume(s *session, volume core.BootVolume, targetAd *string, job *resourceJob, progress func(string)) (core.BootVolume, error) {
+	displayName := common.String(*volume.DisplayName + time.Now().Format("-clone-20060102-1504"))
+
+	var source core.BootVolumeSourceDetails
+	var backupId *string
+	keepBackup := false
+	if *volume.AvailabilityDomain == *targetAd {
+		progress("正在克隆引导卷...")
+		source = core.BootVolumeSourceFromBootVolumeDetails{Id: volume.Id}
+	} else {
+		progress("[1/3] 正在创建完整备份...")
+		backup, err := createBootVolumeBackup(s.storage, volume.Id, core.CreateBootVolumeBackupDetailsTypeFull, job)
+		if err != nil {
+			return core.BootVolume{}, fmt.Errorf("创建备份失败: %v", err)
+		}
+		backupId = backup.Id
+		// 临时备份占用免费备份额度，失败时也要删除
+		defer func() {
+			if keepBackup {
+				return
+			}
+			progress("[3/3] 正在删除临时备份...")
+			if _, err := deleteBootVolumeBackup(s.storage, backup.Id); err != nil {
+				printlnErr("删除临时备份失败", err.Error())
+			}
+		}()
+		err = waitBootVolumeBackupState(s.storage, backup.Id, core.BootVolumeBackupLifecycleStateAvailable)
+		if err != nil {
+			return core.BootVolume{}, err
+		}
+		progress("[2/3] 正在从备份恢复到目标可用性域...")
+		source = core.BootVolumeSourceFromBootVolumeBackupDetails{Id: backup.Id}
+	}
+
+	req := core.CreateBootVolumeRequest{
+		CreateBootVolumeDetails: core.CreateBootVolumeDetails{
+			CompartmentId:      volume.CompartmentId,
+			AvailabilityDomain: targetAd,
+			DisplayName:        displayName,
+			SourceDetails:      source,
+			VpusPerGB:          volume.VpusPerGB,
+			FreeformTags:       job.freeformTags(),
+			DefinedTags:        job.definedTags(),
+		},
+		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+	}
+	resp, err := s.storage.CreateBootVolume(ctx, req)
+	if err != nil {
+		return core.BootVolume{}, fmt.Errorf("创建引导卷失败: %v", err)
+	}
+	err = waitBootVolumeState(s.storage, resp.BootVolume.Id, core.BootVolumeLifecycleStateAvailable)
+	if err != nil && backupId != nil && !isBootVolumeSettled(s.storage, resp.BootVolume.Id) {
+		keepBackup = true
+		err = fmt.Errorf("%v\n新引导卷可能仍在从备份恢复，临时备份 %s 未删除，请在恢复完成后手动删除", err, *backupId)
+	}
+	return resp.BootVolume, err
+}
+
+// 引导卷是否已结束恢复：可用、异常或已删除
+func isBootVolumeSettled(c core.BlockstorageClient, bootVolumeId *string) bool {
+	volume, err := getBootVolume(c, bootVolumeId)
+	if err != nil {
+		return false
+	}
+	switch volume.LifecycleState {
+	case core.BootVolumeLifecycleStateAvailable, core.BootVolumeLifecycleStateFaulty,
+		core.BootVolumeLifecycleStateTerminating, core.BootVolumeLifecycleStateTerminated:
+		return true
+	}
+	return false
+}
+
+// 等待引导卷达到指定状态
+func waitBootVolumeState(c core.BlockstorageClient, bootVolumeId *string, state core.BootVolumeLifecycleStateEnum) error {
+	var current core.BootVolumeLifecycleStateEnum
+	for i := 0; i < 600; i++ {
+		volume, err := getBootVolume(c, bootVolumeId)
+		if err != nil {
+			return err
+		}
+		current = volume.LifecycleState
+		if current == state {
+			return nil
+		}
+		if current == core.BootVolumeLifecycleStateFaulty {
+			return fmt.Errorf("引导卷状态异常: %s", getBootVolumeState(current))
+		}
+		time.Sleep(5 * time.Second)
+	}
+	return fmt.Errorf("等待引导卷状态变为 %s 超时, 当前状态: %s", getBootVolumeState(state), getBootVolumeState(current))
+}
+
+// 等待引导卷备份达到指定状态
+func waitBootVolumeBackupState(c core.BlockstorageClient, backupId *string, state core.BootVolumeBackupLifecycleStateEnum) error {
+	var current core.BootVolumeBackupLifecycleStateEnum
+	for i := 0; i < 600; i++ {
+		req := core.GetBootVolumeBackupRequest{
+			BootVolumeBackupId: backupId,
+			RequestMetadata:    getCustomRequestMetadataWithRetryPolicy(),
+		}
+		resp, err := c.GetBootVolumeBackup(ctx, req)
+		if err != nil {
+			return err
+		}
+		current = resp.LifecycleState
+		if current == state {
+			return nil
+		}
+		if current == core.BootVolumeBackupLifecycleStateFaulty {
+			return fmt.Errorf("引导卷备份状态异常: %s", getBootVolumeBackupState(current))
+		}
+		time.Sleep(5 * time.Second)
+	}
+	return fmt.Errorf("等待引导卷备份状态变为 %s 超时, 当前状态: %s", getBootVolumeBackupState(state), getBootVolumeBackupState(current))
+}
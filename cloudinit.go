@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+)
+
+// 实例元数据 user_data 的大小限制(base64 编码后)
+const cloudInitMaxSize = 32000
+
+// 内置的 cloud-init 脚本片段，在模板中通过 cloud-init-snippets=名称1,名称2 使用
+var cloudInitSnippets = []struct {
+	Name        string
+	Description string
+	Script      func(ins Instance) (string, error)
+}{
+	{"root-ssh", "允许 root 用户通过 SSH 登录，并复制默认用户的 SSH 公钥", func(Instance) (string, error) {
+		return `mkdir -p /root/.ssh
+cat /home/*/.ssh/authorized_keys > /root/.ssh/authorized_keys 2>/dev/null
+chmod 700 /root/.ssh && chmod 600 /root/.ssh/authorized_keys
+rm -f /etc/ssh/sshd_config.d/*cloudimg*.conf
+sed -i 's/^#\?PermitRootLogin.*/PermitRootLogin yes/' /etc/ssh/sshd_config
+sed -i 's/^#\?PasswordAuthentication.*/PasswordAuthentication yes/' /etc/ssh/sshd_config
+systemctl restart sshd 2>/dev/null || systemctl restart ssh`, nil
+	}},
+	{"password", "设置 root 密码为 cloud-init-password", func(ins Instance) (string, error) {
+		if ins.CloudInitPassword == "" {
+			return "", fmt.Errorf("使用 password 片段需要设置 cloud-init-password")
+		}
+		return fmt.Sprintf("echo %s | chpasswd", shellQuote("root:"+ins.CloudInitPassword)), nil
+	}},
+	{"docker", "安装 Docker", func(Instance) (string, error) {
+		return `curl -fsSL https://get.docker.com | sh
+systemctl enable --now docker`, nil
+	}},
+	{"iptables", "开放 Ubuntu 镜像中默认的 iptables 防火墙规则", func(Instance) (string, error) {
+		return `iptables -P INPUT ACCEPT
+iptables -P FORWARD ACCEPT
+iptables -P OUTPUT ACCEPT
+iptables -F
+command -v netfilter-persistent >/dev/null && netfilter-persistent save`, nil
+	}},
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+// 合并模板中的 cloud-init 配置并进行 base64 编码:
+// cloud-init (已编码的内容)、cloud-init-file (文件)、cloud-init-text (明文) 和 cloud-init-snippets (内置片段)。
+// 只有一项时直接使用，多项时合并为 MIME multipart 格式，由 cloud-init 依次执行。
+func buildCloudInit(ins *Instance) error {
+	var parts []string
+	if ins.CloudInit != "" {
+		data, err := base64.StdEncoding.DecodeString(ins.CloudInit)
+		if err != nil {
+			return fmt.Errorf("cloud-init 不是有效的 base64 编码: %v", err)
+		}
+		parts = append(parts, string(data))
+	}
+	if ins.CloudInitFile != "" {
+		data, err := ioutil.ReadFile(expandConfigPath(ins.CloudInitFile))
+		if err != nil {
+			return fmt.Errorf("读取 cloud-init-file 失败: %v", err)
+		}
+		parts = append(parts, string(data))
+	}
+	if strings.TrimSpace(ins.CloudInitText) != "" {
+		parts = append(parts, ins.CloudInitText)
+	}
+	if ins.CloudInitSnippets != "" {
+		script, err := buildCloudInitSnippets(*ins)
+		if err != nil {
+			return err
+		}
+		parts = append(parts, script)
+	}
+
+	var userData string
+	switch len(parts) {
+	case 0:
+		return nil
+	case 1:
+		userData = parts[0]
+	default:
+		var err error
+		if userData, err = buildMultipartUserData(parts); err != nil {
+			return err
+		}
+	}
+	ins.CloudInit = base64.StdEncoding.EncodeToString([]byte(userData))
+	if len(ins.CloudInit) > cloudInitMaxSize {
+		return fmt.Errorf("cloud-init 编码后大小为 %d 字节，超过元数据限制 %d 字节", len(ins.CloudInit), cloudInitMaxSize)
+	}
+	return nil
+}
+
+func buildCloudInitSnippets(ins Instance) (string, error) {
+	var script strings.Builder
+	script.WriteString("#!/bin/bash\n")
+	for _, name := range strings.Split(ins.CloudInitSnippets, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, snippet := range cloudInitSnippets {
+			if snippet.Name != name {
+				continue
+			}
+			found = true
+			content, err := snippet.Script(ins)
+			if err != nil {
+				return "", err
+			}
+			script.WriteString(fmt.Sprintf("\n# %s\n%s\n", snippet.Description, content))
+		}
+		if !found {
+			return "", fmt.Errorf("未知的 cloud-init 片段 %s，可选值: %s", name, strings.Join(getCloudInitSnippetNames(), ", "))
+		}
+	}
+	return script.String(), nil
+}
+
+func getCloudInitSnippetNames() (names []string) {
+	for _, snippet := range cloudInitSnippets {
+		names = append(names, snippet.Name)
+	}
+	return
+}
+
+func buildMultipartUserData(parts []string) (string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	buf.WriteString(fmt.Sprintf("Content-Type: multipart/mixed; boundary=\"%s\"\nMIME-Version: 1.0\n\n", w.Boundary()))
+	for i, part := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", getCloudInitContentType(part)+"; charset=\"utf-8\"")
+		header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"part-%d\"", i+1))
+		pw, err := w.CreatePart(header)
+		if err != nil {
+			return "", err
+		}
+		if getCloudInitContentType(part) == "text/x-shellscript" && !strings.HasPrefix(part, "#!") {
+			part = "#!/bin/bash\n" + part
+		}
+		pw.Write([]byte(part))
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// 根据内容的第一行判断 cloud-init 的类型，无法识别时作为 shell 脚本执行
+func getCloudInitContentType(content string) string {
+	switch {
+	case strings.HasPrefix(content, "#cloud-config"):
+		return "text/cloud-config"
+	case strings.HasPrefix(content, "#include"):
+		return "text/x-include-url"
+	case strings.HasPrefix(content, "#cloud-boothook"):
+		return "text/cloud-boothook"
+	default:
+		return "text/x-shellscript"
+	}
+}
+
+// 将模板转换为实例配置，并生成 cloud-init 和 SSH 公钥
+func mapTemplateToInstance(t LaunchTemplate) (Instance, error) {
+	var ins Instance
+	if err := t.Section.MapTo(&ins); err != nil {
+		return ins, fmt.Errorf("解析实例模板参数失败: %v", err)
+	}
+	if err := buildCloudInit(&ins); err != nil {
+		return ins, err
+	}
+	if err := buildSSHAuthorizedKeys(&ins); err != nil {
+		return ins, err
+	}
+	return ins, nil
+}
+
+func printTemplateCloudInit(t LaunchTemplate) {
+	ins, err := mapTemplateToInstance(t)
+	if err != nil {
+		fmt.Printf("# cloud-init: %v\n", err)
+		return
+	}
+	if ins.CloudInit == "" {
+		fmt.Println("# cloud-init: 未设置")
+		return
+	}
+	data, _ := base64.StdEncoding.DecodeString(ins.CloudInit)
+	fmt.Printf("# cloud-init (编码后 %d / %d 字节):\n%s\n", len(ins.CloudInit), cloudInitMaxSize, data)
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+var (
+	compartmentId        string                 // 当前操作的区间，默认为租户根区间
+	compartmentRecursive bool                   // 列出资源时是否包含子区间
+	listCompartmentIds   []string               // 列出资源时查询的区间
+	compartments         []identity.Compartment // 租户中可访问的所有区间
+)
+
+// 当前操作的区间，创建资源时使用
+func getCompartmentId() *string {
+	return common.String(compartmentId)
+}
+
+// 创建实例的区间，模板中设置了 compartment 时优先使用模板的设置
+func getLaunchCompartmentId(s *session, ins Instance) *string {
+	if ins.Compartment != "" {
+		return common.String(ins.Compartment)
+	}
+	return common.String(s.compartmentId)
+}
+
+// 根据账号配置初始化区间
+func initCompartment() error {
+	compartmentRecursive = oracle.Compartment_recursive
+	compartments = nil
+	id := oracle.Compartment
+	if id == "" {
+		id = oracle.Tenancy
+	}
+	return setCompartment(id)
+}
+
+// 切换当前区间。包含子区间时，重新获取区间列表并计算所有子区间
+func setCompartment(id string) error {
+	ids := []string{id}
+	if compartmentRecursive {
+		all, err := listAllCompartments(identityClient, oracle.Tenancy)
+		if err != nil {
+			return fmt.Errorf("获取区间列表失败: %v", err)
+		}
+		compartments = all
+		ids = append(ids, getSubCompartmentIds(id, all)...)
+	}
+	compartmentId = id
+	listCompartmentIds = ids
+	return nil
+}
+
+// 列出租户中所有可访问的活动区间
+func listAllCompartments(client identity.IdentityClient, tenancy string) ([]identity.Compartment, error) {
+	req := identity.ListCompartmentsRequest{
+		CompartmentId:          common.String(tenancy),
+		CompartmentIdInSubtree: common.Bool(true),
+		AccessLevel:            identity.ListCompartmentsAccessLevelAccessible,
+		LifecycleState:         identity.CompartmentLifecycleStateActive,
+		RequestMetadata:        getCustomRequestMetadataWithRetryPolicy(),
+	}
+	var items []identity.Compartment
+	err := listAllPages(func(page *string) (*string, error) {
+		req.Page = page
+		resp, err := client.ListCompartments(ctx, req)
+		items = append(items, resp.Items...)
+		return resp.OpcNextPage, err
+	})
+	return items, err
+}
+
+// 获取账号配置的区间列表，不影响当前选择的账号
+func getAccountCompartmentIds(p common.ConfigurationProvider, o Oracle) ([]string, error) {
+	id := o.Compartment
+	if id == "" {
+		id = o.Tenancy
+	}
+	if !o.Compartment_recursive {
+		return []string{id}, nil
+	}
+	client, err := identity.NewIdentityClientWithConfigurationProvider(p)
+	if err != nil {
+		return nil, err
+	}
+	setProxyOrNot(&client.BaseClient)
+	setRateLimitInterceptor(&client.BaseClient, o.Tenancy)
+	all, err := listAllCompartments(client, o.Tenancy)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{id}, getSubCompartmentIds(id, all)...), nil
+}
+
+// 获取指定区间下的所有子区间(包括多级子区间)
+func getSubCompartmentIds(parentId string, all []identity.Compartment) []string {
+	children := make(map[string][]string)
+	for _, c := range all {
+		children[*c.CompartmentId] = append(children[*c.CompartmentId], *c.Id)
+	}
+	var ids []string
+	queue := []string{parentId}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, child := range children[id] {
+			ids = append(ids, child)
+			queue = append(queue, child)
+		}
+	}
+	return ids
+}
+
+func getCompartmentName(id string) string {
+	if id == oracle.Tenancy {
+		return "根区间"
+	}
+	for _, c := range compartments {
+		if *c.Id == id {
+			return *c.Name
+		}
+	}
+	return id
+}
+
+func selectCompartmentTelegram(chatID int64) {
+	if compartments == nil {
+		all, err := listAllCompartments(identityClient, oracle.Tenancy)
+		if err != nil {
+			sendErrorMessage(chatID, "获取区间列表失败: "+err.Error())
+			return
+		}
+		compartments = all
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	rootText := "根区间"
+	if compartmentId == oracle.Tenancy {
+		rootText += " (当前)"
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(rootText, "select_compartment:-1"),
+	))
+	// 区间较多时分页显示，避免超出 Telegram 按钮数量的限制
+	indexes := make([]int, len(compartments))
+	for i := range compartments {
+		indexes[i] = i
+	}
+	for _, i := range getPageIndexes("compartments", indexes) {
+		c := compartments[i]
+		text := *c.Name
+		if *c.Id == compartmentId {
+			text += " (当前)"
+		}
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(text, fmt.Sprintf("select_compartment:%d", i)),
+		))
+	}
+	if row := pageButtons("compartments", len(indexes)); row != nil {
+		keyboard = append(keyboard, row)
+	}
+	recursiveText := "包含子区间: 关"
+	if compartmentRecursive {
+		recursiveText = "包含子区间: 开"
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(recursiveText, "toggle_compartment_recursive"),
+		tgbotapi.NewInlineKeyboardButtonData("返回", "account_menu"),
+	))
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("当前区间：%s\n请选择区间：", getCompartmentName(compartmentId)))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	bot.Send(msg)
+}
+
+func selectCompartment(chatID int64, index int) {
+	id := oracle.Tenancy
+	if index >= 0 {
+		if index >= len(compartments) {
+			sendErrorMessage(chatID, "无效的区间选择")
+			return
+		}
+		id = *compartments[index].Id
+	}
+	if err := setCompartment(id); err != nil {
+		sendErrorMessage(chatID, err.Error())
+		return
+	}
+	sendAccountMenu(chatID)
+}
+
+func toggleCompartmentRecursive(chatID int64) {
+	compartmentRecursive = !compartmentRecursive
+	if err := setCompartment(compartmentId); err != nil {
+		compartmentRecursive = !compartmentRecursive
+		sendErrorMessage(chatID, err.Error())
+		return
+	}
+	selectCompartmentTelegram(chatID)
+}
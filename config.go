@@ -0,0 +1,37 @@
+package main
+
+import (
+	"sync/atomic"
+
+	"github.com/go-ini/ini"
+)
+
+// 配置文件中的全局设置。loadConfig 解析完成后整体替换，替换后不再修改。
+// 后台任务 (费用预警、抢机、克隆等) 与主循环并发执行，都通过 getConfig 读取，
+// 重新加载配置时不会读到一半新一半旧的配置。
+type appConfig struct {
+	oracleSections      []*ini.Section
+	instanceBaseSection *ini.Section
+	proxy               string
+	token               string
+	chatId              string
+	cmd                 string
+	each                bool                              // 创建实例时是否发送 Telegram 消息
+	watchConfig         bool                              // 配置文件修改后是否自动重新加载
+	budgetInterval      int                               // 费用检查间隔(分钟)，0 表示不检查
+	budgetThreshold     float64                           // 本月成本阈值，0 表示不检查
+	budgetChargeAlert   bool                              // 任一服务出现非零费用时是否提醒
+	requestRate         float64                           // 每个租户每秒最多发送的请求数，所有任务共用，0 表示不限制
+	freeformTags        map[string]string                 // [DEFAULT] 中的 tags
+	definedTags         map[string]map[string]interface{} // [DEFAULT] 中的 defined_tags
+}
+
+var currentConfig atomic.Value
+
+// 返回当前配置，加载配置前返回空配置
+func getConfig() *appConfig {
+	if c, ok := currentConfig.Load().(*appConfig); ok {
+		return c
+	}
+	return &appConfig{}
+}
@@ -0,0 +1,471 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/usageapi"
+)
+
+const costDateLayout = "20060102"
+
+// 成本分组方式对应 Usage API 的 groupBy 字段
+var costGroupByKeys = map[string]string{
+	"service":     "service",
+	"sku":         "skuName",
+	"resource":    "resourceId",
+	"compartment": "compartmentName",
+	"region":      "region",
+}
+
+var costGroupByNames = map[string]string{
+	"service":     "服务",
+	"sku":         "SKU",
+	"resource":    "资源",
+	"compartment": "区间",
+	"region":      "区域",
+}
+
+type CostItem struct {
+	TimeUsageStarted time.Time `json:"timeUsageStarted"`
+	Group            string    `json:"group"`
+	Amount           float32   `json:"amount"`
+	Quantity         float32   `json:"quantity"`
+	Unit             string    `json:"unit"`
+	Currency         string    `json:"currency"`
+}
+
+type CostReport struct {
+	Start       time.Time  `json:"start"`
+	End         time.Time  `json:"end"`
+	Granularity string     `json:"granularity"`
+	GroupBy     string     `json:"groupBy"`
+	Total       float32    `json:"total"`
+	Currency    string     `json:"currency"`
+	Items       []CostItem `json:"items"`
+}
+
+// 解析成本查询的时间范围，返回的结束时间不包含在范围内
+// month: 本月; last_month: 上月; 7d: 最近7天; 20240101-20240131: 自定义日期范围
+func parseCostPeriod(period string) (start, end time.Time, err error) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch period {
+	case "", "month":
+		start, end = currMouthFirstLastDay()
+	case "last_month":
+		end = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		start = end.AddDate(0, -1, 0)
+	case "7d":
+		end = today.AddDate(0, 0, 1)
+		start = end.AddDate(0, 0, -7)
+	default:
+		parts := strings.Split(period, "-")
+		if len(parts) != 2 {
+			err = fmt.Errorf("无效的时间范围: %s", period)
+			return
+		}
+		start, err = time.Parse(costDateLayout, parts[0])
+		if err != nil {
+			return
+		}
+		end, err = time.Parse(costDateLayout, parts[1])
+		if err != nil {
+			return
+		}
+		end = end.AddDate(0, 0, 1)
+		if !start.Before(end) {
+			err = errors.New("开始日期不能晚于结束日期")
+		}
+	}
+	return
+}
+
+func getCostPeriodName(period string) string {
+	switch period {
+	case "", "month":
+		return "本月"
+	case "last_month":
+		return "上月"
+	case "7d":
+		return "最近7天"
+	default:
+		return period
+	}
+}
+
+func newUsageapiClient(p common.ConfigurationProvider) (usageapi.UsageapiClient, error) {
+	client, err := usageapi.NewUsageapiClientWithConfigurationProvider(p)
+	if err != nil {
+		return client, err
+	}
+	tenancy, err := p.TenancyOCID()
+	if err != nil {
+		return client, err
+	}
+	setProxyOrNot(&client.BaseClient)
+	setRateLimitInterceptor(&client.BaseClient, tenancy)
+	return client, nil
+}
+
+// 查询指定时间范围内的成本，groupBy 为 costGroupByKeys 中的键
+func getCostReport(p common.ConfigurationProvider, start, end time.Time, granularity usageapi.RequestSummarizedUsagesDetailsGranularityEnum, groupBy string) (report CostReport, err error) {
+	groupKey, ok := costGroupByKeys[groupBy]
+	if !ok {
+		err = fmt.Errorf("无效的分组方式: %s", groupBy)
+		return
+	}
+	client, err := newUsageapiClient(p)
+	if err != nil {
+		return
+	}
+	tenancyOCID, err := p.TenancyOCID()
+	if err != nil {
+		return
+	}
+
+	report = CostReport{Start: start, End: end, Granularity: string(granularity), GroupBy: groupBy}
+	req := usageapi.RequestSummarizedUsagesRequest{
+		RequestSummarizedUsagesDetails: usageapi.RequestSummarizedUsagesDetails{
+			CompartmentDepth: common.Float32(6),
+			Granularity:      granularity,
+			GroupBy:          []string{groupKey},
+			QueryType:        usageapi.RequestSummarizedUsagesDetailsQueryTypeUsage,
+			TenantId:         &tenancyOCID,
+			TimeUsageStarted: &common.SDKTime{Time: start},
+			TimeUsageEnded:   &common.SDKTime{Time: end},
+		},
+	}
+	for {
+		var resp usageapi.RequestSummarizedUsagesResponse
+		resp, err = client.RequestSummarizedUsages(ctx, req)
+		if err != nil {
+			return
+		}
+		for _, item := range resp.Items {
+			if item.ComputedAmount == nil {
+				continue // 跳过无效的数据
+			}
+			costItem := CostItem{
+				TimeUsageStarted: item.TimeUsageStarted.Time,
+				Group:            getCostGroupValue(item, groupBy),
+				Amount:           *item.ComputedAmount,
+			}
+			if item.ComputedQuantity != nil {
+				costItem.Quantity = *item.ComputedQuantity
+			}
+			if item.Unit != nil {
+				costItem.Unit = *item.Unit
+			}
+			if item.Currency != nil {
+				costItem.Currency = *item.Currency
+				report.Currency = *item.Currency
+			}
+			report.Total += costItem.Amount
+			report.Items = append(report.Items, costItem)
+		}
+		if resp.OpcNextPage == nil {
+			break
+		}
+		req.Page = resp.OpcNextPage
+	}
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		if !report.Items[i].TimeUsageStarted.Equal(report.Items[j].TimeUsageStarted) {
+			return report.Items[i].TimeUsageStarted.Before(report.Items[j].TimeUsageStarted)
+		}
+		return report.Items[i].Amount > report.Items[j].Amount
+	})
+	return
+}
+
+// 预测本月月底的总成本，返回本月已产生的成本和预测的总成本
+func getMonthEndForecast(p common.ConfigurationProvider) (actual, forecast float32, err error) {
+	client, err := newUsageapiClient(p)
+	if err != nil {
+		return
+	}
+	tenancyOCID, err := p.TenancyOCID()
+	if err != nil {
+		return
+	}
+	firstDay, lastDay := currMouthFirstLastDay()
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	req := usageapi.RequestSummarizedUsagesRequest{
+		RequestSummarizedUsagesDetails: usageapi.RequestSummarizedUsagesDetails{
+			Granularity:      usageapi.RequestSummarizedUsagesDetailsGranularityDaily,
+			QueryType:        usageapi.RequestSummarizedUsagesDetailsQueryTypeCost,
+			TenantId:         &tenancyOCID,
+			TimeUsageStarted: &common.SDKTime{Time: firstDay},
+			TimeUsageEnded:   &common.SDKTime{Time: today},
+			Forecast: &usageapi.Forecast{
+				ForecastType:        usageapi.ForecastForecastTypeBasic,
+				TimeForecastStarted: &common.SDKTime{Time: today},
+				TimeForecastEnded:   &common.SDKTime{Time: lastDay},
+			},
+		},
+	}
+	// 每月第一天没有历史数据，无法预测
+	if !today.After(firstDay) {
+		req.TimeUsageEnded = &common.SDKTime{Time: today.AddDate(0, 0, 1)}
+		req.Forecast = nil
+	}
+	resp, err := client.RequestSummarizedUsages(ctx, req)
+	if err != nil {
+		return
+	}
+	for _, item := range resp.Items {
+		if item.ComputedAmount == nil {
+			continue
+		}
+		if item.IsForecast == nil || !*item.IsForecast {
+			actual += *item.ComputedAmount
+		}
+		forecast += *item.ComputedAmount
+	}
+	return
+}
+
+func getCostGroupValue(item usageapi.UsageSummary, groupBy string) string {
+	var value *string
+	switch groupBy {
+	case "service":
+		value = item.Service
+	case "sku":
+		value = item.SkuName
+	case "resource":
+		value = item.ResourceId
+	case "compartment":
+		value = item.CompartmentName
+	case "region":
+		value = item.Region
+	}
+	if value == nil || *value == "" {
+		return "-"
+	}
+	return *value
+}
+
+func parseCostGranularity(granularity string) (usageapi.RequestSummarizedUsagesDetailsGranularityEnum, error) {
+	switch strings.ToLower(granularity) {
+	case "", "monthly":
+		return usageapi.RequestSummarizedUsagesDetailsGranularityMonthly, nil
+	case "daily":
+		return usageapi.RequestSummarizedUsagesDetailsGranularityDaily, nil
+	default:
+		return "", fmt.Errorf("无效的粒度: %s", granularity)
+	}
+}
+
+func viewCostTelegram(chatID int64) {
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("本月", "cost_period:month"),
+			tgbotapi.NewInlineKeyboardButtonData("上月", "cost_period:last_month"),
+			tgbotapi.NewInlineKeyboardButtonData("最近7天", "cost_period:7d"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("自定义范围", "cost_period:custom"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("返回", "select_account:"+strconv.Itoa(getCurrentAccountIndex())),
+		),
+	)
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("查看成本 (当前账号: %s)\n请选择时间范围：", oracleSectionName))
+	msg.ReplyMarkup = keyboard
+	bot.Send(msg)
+}
+
+// 选择分组方式和粒度
+func selectCostGroupBy(chatID int64, period string) {
+	if period == "custom" {
+		msg := tgbotapi.NewMessage(chatID, "请输入开始日期和结束日期，以空格分隔，例如：\n2024-01-01 2024-01-31")
+		msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, Selective: true}
+		bot.Send(msg)
+		setUserState(chatID, "custom_cost_range", 0)
+		return
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, groupBy := range []string{"service", "sku", "resource", "compartment", "region"} {
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("按"+costGroupByNames[groupBy]+" (按月)", fmt.Sprintf("cost_report:%s:%s:monthly", period, groupBy)),
+			tgbotapi.NewInlineKeyboardButtonData("按"+costGroupByNames[groupBy]+" (按日)", fmt.Sprintf("cost_report:%s:%s:daily", period, groupBy)),
+		))
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("返回", "account_action:view_cost"),
+	))
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("时间范围: %s\n请选择分组方式：", getCostPeriodName(period)))
+	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	bot.Send(msg)
+}
+
+func handleCustomCostRange(chatID int64, text string) {
+	fields := strings.Fields(text)
+	if len(fields) != 2 {
+		sendErrorMessage(chatID, "输入格式错误，例如: 2024-01-01 2024-01-31")
+		return
+	}
+	var dates []string
+	for _, field := range fields {
+		t, err := time.Parse("2006-01-02", field)
+		if err != nil {
+			sendErrorMessage(chatID, "无效的日期: "+field)
+			return
+		}
+		dates = append(dates, t.Format(costDateLayout))
+	}
+	period := strings.Join(dates, "-")
+	if _, _, err := parseCostPeriod(period); err != nil {
+		sendErrorMessage(chatID, err.Error())
+		return
+	}
+	selectCostGroupBy(chatID, period)
+}
+
+func sendCostReportTelegram(chatID int64, period, groupBy, granularity string) {
+	msg := tgbotapi.NewMessage(chatID, "正在获取成本数据...")
+	sentMsg, _ := bot.Send(msg)
+
+	start, end, err := parseCostPeriod(period)
+	if err != nil {
+		editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, err.Error())
+		bot.Send(editMsg)
+		return
+	}
+	g, err := parseCostGranularity(granularity)
+	if err != nil {
+		editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, err.Error())
+		bot.Send(editMsg)
+		return
+	}
+	report, err := getCostReport(provider, start, end, g, groupBy)
+	if err != nil {
+		editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, "获取成本数据失败: "+err.Error())
+		bot.Send(editMsg)
+		return
+	}
+
+	var messageText strings.Builder
+	messageText.WriteString(fmt.Sprintf("成本概览 (%s ~ %s，按%s)：\n\n",
+		start.Format("2006-01-02"), end.AddDate(0, 0, -1).Format("2006-01-02"), costGroupByNames[groupBy]))
+	var lastDay string
+	for _, item := range report.Items {
+		if g == usageapi.RequestSummarizedUsagesDetailsGranularityDaily {
+			day := item.TimeUsageStarted.Format("2006-01-02")
+			if day != lastDay {
+				messageText.WriteString(fmt.Sprintf("[%s]\n", day))
+				lastDay = day
+			}
+		}
+		messageText.WriteString(fmt.Sprintf("%s: %.2f %s (使用量: %.2f %s)\n", item.Group, item.Amount, item.Currency, item.Quantity, item.Unit))
+	}
+	messageText.WriteString(fmt.Sprintf("\n总成本: %.2f %s\n", report.Total, report.Currency))
+
+	if period == "" || period == "month" {
+		actual, forecast, err := getMonthEndForecast(provider)
+		if err != nil {
+			messageText.WriteString("月底预测获取失败: " + err.Error() + "\n")
+		} else {
+			messageText.WriteString(fmt.Sprintf("本月已产生: %.2f，预计月底: %.2f %s\n", actual, forecast, report.Currency))
+		}
+	}
+
+	// Telegram 单条消息最多 4096 个字符
+	text := messageText.String()
+	if runes := []rune(text); len(runes) > 4000 {
+		text = string(runes[:4000]) + "\n...(内容过多已截断，请使用命令行导出完整数据)"
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("返回", "account_action:view_cost"),
+		),
+	)
+	editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, text)
+	editMsg.ReplyMarkup = &keyboard
+	bot.Send(editMsg)
+}
+
+// 命令行导出成本数据
+// oci-help cost -account 账号名称 -period month -group service -granularity daily -format csv
+func runCostCommand(args []string) {
+	fs := flag.NewFlagSet("cost", flag.ExitOnError)
+	accountName := fs.String("account", "", "账号名称，默认使用第一个账号")
+	period := fs.String("period", "month", "时间范围: month | last_month | 7d | 20240101-20240131")
+	groupBy := fs.String("group", "service", "分组方式: service | sku | resource | compartment | region")
+	granularity := fs.String("granularity", "monthly", "粒度: monthly | daily")
+	format := fs.String("format", "csv", "输出格式: csv | json")
+	fs.Parse(args)
+
+	sections := getConfig().oracleSections
+	sec := sections[0]
+	if *accountName != "" {
+		sec = nil
+		for _, s := range sections {
+			if s.Name() == *accountName {
+				sec = s
+			}
+		}
+		if sec == nil {
+			log.Fatalf("未找到账号: %s", *accountName)
+		}
+	}
+	oracleSection = sec
+	if err := initVar(sec); err != nil {
+		log.Fatalf("初始化账户失败: %v", err)
+	}
+
+	start, end, err := parseCostPeriod(*period)
+	if err != nil {
+		log.Fatal(err)
+	}
+	g, err := parseCostGranularity(*granularity)
+	if err != nil {
+		log.Fatal(err)
+	}
+	report, err := getCostReport(provider, start, end, g, *groupBy)
+	if err != nil {
+		log.Fatalf("获取成本数据失败: %v", err)
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"date", *groupBy, "amount", "currency", "quantity", "unit"})
+		for _, item := range report.Items {
+			w.Write([]string{
+				item.TimeUsageStarted.Format("2006-01-02"),
+				item.Group,
+				strconv.FormatFloat(float64(item.Amount), 'f', 4, 32),
+				item.Currency,
+				strconv.FormatFloat(float64(item.Quantity), 'f', 4, 32),
+				item.Unit,
+			})
+		}
+		w.Flush()
+		err = w.Error()
+	default:
+		err = fmt.Errorf("无效的输出格式: %s", *format)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -5,5 +5,9 @@ go 1.17
 require (
 	github.com/oracle/oci-go-sdk/v54 v54.0.0
 	github.com/sony/gobreaker v0.4.2-0.20210216022020-dd874f9dd33b // indirect
+	golang.org/x/crypto v0.14.0
+	golang.org/x/sys v0.15.0 // indirect
+	golang.org/x/term v0.15.0
 	gopkg.in/ini.v1 v1.63.2
+	gopkg.in/yaml.v3 v3.0.1
 )
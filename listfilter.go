@@ -0,0 +1,460 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// 列表排序方式，未设置时使用 API 返回的顺序
+const (
+	listSortName    = "name"
+	listSortCreated = "created"
+	listSortState   = "state"
+)
+
+var listSortNames = []struct {
+	Key  string
+	Name string
+}{
+	{listSortName, "名称"},
+	{listSortCreated, "创建时间"},
+	{listSortState, "状态"},
+}
+
+// 实例和卷列表的筛选和排序条件
+type listFilter struct {
+	State          string // 状态，多个以逗号分隔，例如 RUNNING,STOPPED
+	Shape          string // 实例形状包含的文本
+	AD             string // 可用性域包含的文本
+	Name           string // 名称包含的文本
+	Tag            string // 标签，格式见 matchTagFilter
+	Sort           string
+	ShowTerminated bool // 默认不显示已终止的资源，筛选状态为 TERMINATED 时也会显示
+}
+
+// Telegram 中每个列表单独保存筛选条件
+var listFilters = make(map[string]*listFilter)
+
+func getListFilter(target string) *listFilter {
+	f, ok := listFilters[target]
+	if !ok {
+		f = &listFilter{}
+		listFilters[target] = f
+	}
+	return f
+}
+
+// 列表中的一个资源，Index 为资源在完整列表中的下标
+type listItem struct {
+	Index        int
+	Name         string
+	State        string
+	StateText    string
+	Shape        string
+	AD           string
+	SizeInGBs    int64
+	TimeCreated  time.Time
+	FreeformTags map[string]string
+	DefinedTags  map[string]map[string]interface{}
+}
+
+func instanceListItems(instances []core.Instance) []listItem {
+	items := make([]listItem, len(instances))
+	for i, ins := range instances {
+		items[i] = listItem{
+			Index:        i,
+			Name:         *ins.DisplayName,
+			State:        string(ins.LifecycleState),
+			StateText:    getInstanceState(ins.LifecycleState),
+			Shape:        *ins.Shape,
+			AD:           *ins.AvailabilityDomain,
+			TimeCreated:  sdkTime(ins.TimeCreated),
+			FreeformTags: ins.FreeformTags,
+			DefinedTags:  ins.DefinedTags,
+		}
+	}
+	return items
+}
+
+func bootVolumeListItems(volumes []core.BootVolume) []listItem {
+	items := make([]listItem, len(volumes))
+	for i, volume := range volumes {
+		items[i] = listItem{
+			Index:        i,
+			Name:         *volume.DisplayName,
+			State:        string(volume.LifecycleState),
+			StateText:    getBootVolumeState(volume.LifecycleState),
+			AD:           *volume.AvailabilityDomain,
+			SizeInGBs:    *volume.SizeInGBs,
+			TimeCreated:  sdkTime(volume.TimeCreated),
+			FreeformTags: volume.FreeformTags,
+			DefinedTags:  volume.DefinedTags,
+		}
+	}
+	return items
+}
+
+func blockVolumeListItems(volumes []core.Volume) []listItem {
+	items := make([]listItem, len(volumes))
+	for i, volume := range volumes {
+		items[i] = listItem{
+			Index:        i,
+			Name:         *volume.DisplayName,
+			State:        string(volume.LifecycleState),
+			StateText:    getVolumeState(volume.LifecycleState),
+			AD:           *volume.AvailabilityDomain,
+			SizeInGBs:    *volume.SizeInGBs,
+			TimeCreated:  sdkTime(volume.TimeCreated),
+			FreeformTags: volume.FreeformTags,
+			DefinedTags:  volume.DefinedTags,
+		}
+	}
+	return items
+}
+
+func sdkTime(t *common.SDKTime) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.Time
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func (f *listFilter) match(item listItem) bool {
+	if f.State != "" {
+		matched := false
+		for _, state := range strings.Split(f.State, ",") {
+			if strings.EqualFold(strings.TrimSpace(state), item.State) {
+				matched = true
+			}
+		}
+		if !matched {
+			return false
+		}
+	} else if !f.ShowTerminated && item.State == "TERMINATED" {
+		return false
+	}
+	return containsFold(item.Shape, f.Shape) &&
+		containsFold(item.AD, f.AD) &&
+		containsFold(item.Name, f.Name) &&
+		matchTagFilter(f.Tag, item.FreeformTags, item.DefinedTags)
+}
+
+// 返回符合条件的资源，并按设置的方式排序
+func (f *listFilter) apply(items []listItem) []listItem {
+	var result []listItem
+	for _, item := range items {
+		if f.match(item) {
+			result = append(result, item)
+		}
+	}
+	switch f.Sort {
+	case listSortName:
+		sort.SliceStable(result, func(i, j int) bool {
+			return strings.ToLower(result[i].Name) < strings.ToLower(result[j].Name)
+		})
+	case listSortCreated:
+		sort.SliceStable(result, func(i, j int) bool {
+			return result[i].TimeCreated.After(result[j].TimeCreated)
+		})
+	case listSortState:
+		sort.SliceStable(result, func(i, j int) bool {
+			if result[i].State != result[j].State {
+				return result[i].State < result[j].State
+			}
+			return strings.ToLower(result[i].Name) < strings.ToLower(result[j].Name)
+		})
+	}
+	return result
+}
+
+// 筛选条件的说明，使用默认条件时返回空字符串
+func (f *listFilter) String() string {
+	var parts []string
+	if f.State != "" {
+		parts = append(parts, "状态="+f.State)
+	} else if f.ShowTerminated {
+		parts = append(parts, "包含已终止")
+	}
+	if f.Shape != "" {
+		parts = append(parts, "形状包含 "+f.Shape)
+	}
+	if f.AD != "" {
+		parts = append(parts, "可用性域包含 "+f.AD)
+	}
+	if f.Name != "" {
+		parts = append(parts, "名称包含 "+f.Name)
+	}
+	if f.Tag != "" {
+		parts = append(parts, "标签 "+f.Tag)
+	}
+	if name := getListSortName(f.Sort); name != "" {
+		parts = append(parts, "按"+name+"排序")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func getListSortName(key string) string {
+	for _, s := range listSortNames {
+		if s.Key == key {
+			return s.Name
+		}
+	}
+	return ""
+}
+
+// 返回列表中符合筛选条件的资源下标，并在消息中添加筛选条件说明
+func getFilteredIndexes(target string, items []listItem, messageText *strings.Builder) []int {
+	f := getListFilter(target)
+	if text := f.String(); text != "" {
+		messageText.WriteString(fmt.Sprintf("筛选: %s\n", text))
+	}
+	var indexes []int
+	for _, item := range f.apply(items) {
+		indexes = append(indexes, item.Index)
+	}
+	return indexes
+}
+
+// 列表底部的筛选按钮，target 为筛选后重新显示的列表
+func listFilterButtons(target string) []tgbotapi.InlineKeyboardButton {
+	filterText := "筛选/排序"
+	if getListFilter(target).String() != "" {
+		filterText = "筛选/排序 (已设置)"
+	}
+	ownText := "仅显示本工具创建"
+	if getListFilter(target).Tag == ownTagFilter() {
+		ownText = "显示全部"
+	}
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(filterText, "list_filter:"+target),
+		tgbotapi.NewInlineKeyboardButtonData(ownText, "tag_filter_own:"+target),
+		refreshButton(target),
+	)
+}
+
+func ownTagFilter() string {
+	return tagCreatedByKey + "=" + tagCreatedByValue
+}
+
+func showListFilterMenu(chatID int64, target string) {
+	f := getListFilter(target)
+	text := f.String()
+	if text == "" {
+		text = "无"
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("状态", "list_filter_set:"+target+":state"),
+		tgbotapi.NewInlineKeyboardButtonData("名称", "list_filter_set:"+target+":name"),
+		tgbotapi.NewInlineKeyboardButtonData("可用性域", "list_filter_set:"+target+":ad"),
+	))
+	row := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("标签", "list_filter_set:"+target+":tag"),
+	)
+	if target == "instances" {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("形状", "list_filter_set:"+target+":shape"))
+	}
+	keyboard = append(keyboard, row)
+
+	var sortRow []tgbotapi.InlineKeyboardButton
+	for _, s := range listSortNames {
+		name := "按" + s.Name + "排序"
+		if f.Sort == s.Key {
+			name = "✅ " + name
+		}
+		sortRow = append(sortRow, tgbotapi.NewInlineKeyboardButtonData(name, "list_sort:"+target+":"+s.Key))
+	}
+	keyboard = append(keyboard, sortRow)
+
+	// 块存储卷列表不包含已终止的卷
+	if target != "block_volumes" {
+		terminatedText := "显示已终止: 否"
+		if f.ShowTerminated {
+			terminatedText = "显示已终止: 是"
+		}
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(terminatedText, "list_filter_terminated:"+target),
+		))
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("清除筛选", "list_filter_clear:"+target),
+		tgbotapi.NewInlineKeyboardButtonData("返回列表", fmt.Sprintf("list_page:%s:%d", target, listPages[target])),
+	))
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("筛选和排序\n当前条件: %s\n请选择要设置的条件：", text))
+	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	bot.Send(msg)
+}
+
+func promptListFilter(chatID int64, target, field string) {
+	var text string
+	switch field {
+	case "state":
+		text = "请输入状态，多个以逗号分隔，例如 RUNNING,STOPPED 或 AVAILABLE："
+	case "name":
+		text = "请输入名称包含的文本："
+	case "ad":
+		text = "请输入可用性域包含的文本，例如 AD-1："
+	case "shape":
+		text = "请输入形状包含的文本，例如 A1.Flex："
+	case "tag":
+		text = "请输入标签，格式为 key=value 或 key，定义标签使用 namespace.key=value："
+	default:
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, text+"\n输入 - 清除该条件")
+	msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, Selective: true}
+	bot.Send(msg)
+	setUserStateData(chatID, "setting_list_filter", 0, target+":"+field)
+}
+
+func handleListFilter(chatID int64, data, text string) {
+	parts := strings.SplitN(data, ":", 2)
+	if len(parts) != 2 {
+		return
+	}
+	target, field := parts[0], parts[1]
+	text = strings.TrimSpace(text)
+	if text == "-" {
+		text = ""
+	}
+	f := getListFilter(target)
+	switch field {
+	case "state":
+		f.State = strings.ToUpper(strings.ReplaceAll(text, " ", ""))
+	case "name":
+		f.Name = text
+	case "ad":
+		f.AD = text
+	case "shape":
+		f.Shape = text
+	case "tag":
+		if text != "" && strings.TrimSpace(strings.SplitN(text, "=", 2)[0]) == "" {
+			sendErrorMessage(chatID, "标签格式错误，应为 key=value 或 key")
+			return
+		}
+		f.Tag = text
+	}
+	showListPage(chatID, target, 0)
+}
+
+// 再次选择当前的排序方式时恢复默认顺序
+func setListSort(chatID int64, target, key string) {
+	f := getListFilter(target)
+	if f.Sort == key {
+		f.Sort = ""
+	} else {
+		f.Sort = key
+	}
+	showListPage(chatID, target, 0)
+}
+
+func toggleListTerminated(chatID int64, target string) {
+	f := getListFilter(target)
+	f.ShowTerminated = !f.ShowTerminated
+	showListPage(chatID, target, 0)
+}
+
+func clearListFilter(chatID int64, target string) {
+	listFilters[target] = &listFilter{}
+	showListPage(chatID, target, 0)
+}
+
+// 切换是否只显示本工具创建的资源
+func toggleOwnTagFilter(chatID int64, target string) {
+	f := getListFilter(target)
+	if f.Tag == ownTagFilter() {
+		f.Tag = ""
+	} else {
+		f.Tag = ownTagFilter()
+	}
+	showListPage(chatID, target, 0)
+}
+
+// 命令行列出账号中的实例或卷
+func runListCommand(args []string) {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	accountName := fs.String("account", "", "账号名称，默认使用第一个账号")
+	resourceType := fs.String("type", "instances", "资源类型: instances | boot-volumes | block-volumes")
+	var f listFilter
+	fs.StringVar(&f.State, "state", "", "状态，多个以逗号分隔，例如 RUNNING,STOPPED")
+	fs.StringVar(&f.Shape, "shape", "", "形状包含的文本")
+	fs.StringVar(&f.AD, "ad", "", "可用性域包含的文本")
+	fs.StringVar(&f.Name, "name", "", "名称包含的文本")
+	fs.StringVar(&f.Tag, "tag", "", "标签: key=value | key | namespace.key=value")
+	fs.StringVar(&f.Sort, "sort", "", "排序: name | created | state")
+	fs.BoolVar(&f.ShowTerminated, "all", false, "显示已终止的资源")
+	fs.Parse(args)
+	if f.Sort != "" && getListSortName(f.Sort) == "" {
+		log.Fatalf("不支持的排序方式: %s", f.Sort)
+	}
+
+	sections := getConfig().oracleSections
+	sec := sections[0]
+	if *accountName != "" {
+		sec = nil
+		for _, s := range sections {
+			if s.Name() == *accountName {
+				sec = s
+			}
+		}
+		if sec == nil {
+			log.Fatalf("未找到账号: %s", *accountName)
+		}
+	}
+	oracleSection = sec
+	if err := initVar(sec); err != nil {
+		log.Fatalf("初始化账户失败: %v", err)
+	}
+
+	var items []listItem
+	switch *resourceType {
+	case "instances":
+		instances, err := ListInstances(ctx, computeClient)
+		if err != nil {
+			log.Fatalf("获取实例失败: %v", err)
+		}
+		items = instanceListItems(instances)
+	case "boot-volumes":
+		items = bootVolumeListItems(getAllBootVolumes())
+	case "block-volumes":
+		volumes, err := listBlockVolumes()
+		if err != nil {
+			log.Fatal(err)
+		}
+		items = blockVolumeListItems(volumes)
+	default:
+		log.Fatalf("不支持的资源类型: %s", *resourceType)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "名称\t状态\t形状/大小\t可用性域\t创建时间\t标签")
+	for _, item := range f.apply(items) {
+		size := item.Shape
+		if size == "" {
+			size = fmt.Sprintf("%d GB", item.SizeInGBs)
+		}
+		created := "-"
+		if !item.TimeCreated.IsZero() {
+			created = item.TimeCreated.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", item.Name, item.StateText, size, item.AD, created,
+			formatTags(item.FreeformTags, item.DefinedTags))
+	}
+	w.Flush()
+}
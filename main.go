@@ -20,9 +20,9 @@ import (
 	"time"
 
 	"github.com/go-ini/ini"
-	"github.com/oracle/oci-go-sdk/v65/usageapi"
 
 	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/common/auth"
 	"github.com/oracle/oci-go-sdk/v65/core"
 	"github.com/oracle/oci-go-sdk/v65/example/helpers"
 	"github.com/oracle/oci-go-sdk/v65/identity"
@@ -45,34 +45,36 @@ var (
 	storageClient       core.BlockstorageClient
 	identityClient      identity.IdentityClient
 	ctx                 context.Context = context.Background()
-	oracleSections      []*ini.Section
 	oracleSection       *ini.Section
 	oracleSectionName   string
 	oracle              Oracle
-	instanceBaseSection *ini.Section
 	instance            Instance
-	proxy               string
-	token               string
-	chat_id             string
-	cmd                 string
-	sendMessageUrl      string
-	editMessageUrl      string
-	EACH                bool
 	availabilityDomains []identity.AvailabilityDomain
 )
 
 type Oracle struct {
-	User         string `ini:"user"`
-	Fingerprint  string `ini:"fingerprint"`
-	Tenancy      string `ini:"tenancy"`
-	Region       string `ini:"region"`
-	Key_file     string `ini:"key_file"`
-	Key_password string `ini:"key_password"`
+	User                  string `ini:"user"`
+	Fingerprint           string `ini:"fingerprint"`
+	Tenancy               string `ini:"tenancy"`
+	Region                string `ini:"region"`
+	Key_file              string `ini:"key_file"`
+	Key_password          string `ini:"key_password"`
+	Key                   string `ini:"key"`
+	Key_env               string `ini:"key_env"`
+	Config_file           string `ini:"config_file"`
+	Profile               string `ini:"profile"`
+	Auth                  string `ini:"auth"`
+	Regions               string `ini:"regions"`
+	Compartment           string `ini:"compartment"`
+	Compartment_recursive bool   `ini:"compartment_recursive"`
 }
 
 type Instance struct {
 	AvailabilityDomain     string  `ini:"availabilityDomain"`
 	SSH_Public_Key         string  `ini:"ssh_authorized_key"`
+	SSHKeyFiles            string  `ini:"ssh_authorized_key_files"`
+	GenerateSSHKey         bool    `ini:"ssh_generate_key"`
+	AllowNoSSHKey          bool    `ini:"allow_no_ssh_key"`
 	VcnDisplayName         string  `ini:"vcnDisplayName"`
 	SubnetDisplayName      string  `ini:"subnetDisplayName"`
 	Shape                  string  `ini:"shape"`
@@ -83,10 +85,18 @@ type Instance struct {
 	MemoryInGBs            float32 `ini:"memoryInGBs"`
 	Burstable              string  `ini:"burstable"`
 	BootVolumeSizeInGBs    int64   `ini:"bootVolumeSizeInGBs"`
+	BootVolumeId           string  `ini:"bootVolumeId"`
+	Compartment            string  `ini:"compartment"`
 	Sum                    int32   `ini:"sum"`
 	Each                   int32   `ini:"each"`
 	Retry                  int32   `ini:"retry"`
 	CloudInit              string  `ini:"cloud-init"`
+	CloudInitFile          string  `ini:"cloud-init-file"`
+	CloudInitText          string  `ini:"cloud-init-text"`
+	CloudInitSnippets      string  `ini:"cloud-init-snippets"`
+	CloudInitPassword      string  `ini:"cloud-init-password"`
+	Tags                   string  `ini:"tags"`
+	DefinedTags            string  `ini:"defined_tags"`
 	MinTime                int32   `ini:"minTime"`
 	MaxTime                int32   `ini:"maxTime"`
 }
@@ -106,68 +116,130 @@ func main() {
 	flag.StringVar(&configFilePath, "c", defConfigFilePath, "配置文件路径")
 	flag.Parse()
 
-	cfg, err := ini.Load(configFilePath)
+	cfg, err := loadConfigFile(configFilePath)
 	helpers.FatalIfError(err)
-
-	defSec := cfg.Section(ini.DefaultSection)
-	proxy = defSec.Key("proxy").Value()
-	token = defSec.Key("token").Value()
-	chat_id = defSec.Key("chat_id").Value()
-	cmd = defSec.Key("cmd").Value()
-	if defSec.HasKey("EACH") {
-		EACH, _ = defSec.Key("EACH").Bool()
-	} else {
-		EACH = true
+	switch flag.Arg(0) {
+	case "secrets":
+		runSecretsCommand(cfg, flag.Args()[1:])
+		return
+	case "config":
+		runConfigCommand(cfg, flag.Args()[1:])
+		return
+	}
+	// 在加载配置前检查，配置有误时也能输出所有问题
+	if flag.Arg(0) == "validate" {
+		runValidateCommand(cfg, flag.Args()[1:])
+		return
+	}
+	issues := validateConfig(cfg)
+	for _, issue := range issues {
+		log.Println(issue.String())
+	}
+	if hasConfigErrors(issues) {
+		log.Fatal("配置检查未通过，请修改配置文件后重新运行")
+	}
+	err = loadConfig(cfg)
+	if err != nil {
+		log.Fatal(err)
 	}
-	sendMessageUrl = "https://api.telegram.org/bot" + token + "/sendMessage"
-	editMessageUrl = "https://api.telegram.org/bot" + token + "/editMessageText"
 	rand.Seed(time.Now().UnixNano())
 
-	sections := cfg.Sections()
-	oracleSections = []*ini.Section{}
-	for _, sec := range sections {
-		if len(sec.ParentKeys()) == 0 {
-			user := sec.Key("user").Value()
-			fingerprint := sec.Key("fingerprint").Value()
-			tenancy := sec.Key("tenancy").Value()
-			region := sec.Key("region").Value()
-			key_file := sec.Key("key_file").Value()
-			if user != "" && fingerprint != "" && tenancy != "" && region != "" && key_file != "" {
-				oracleSections = append(oracleSections, sec)
-			}
-		}
-	}
-	if len(oracleSections) == 0 {
-		log.Fatalf("未找到正确的配置信息, 请参考链接文档配置相关信息。链接: https://github.com/lemoex/oci-help")
+	switch flag.Arg(0) {
+	case "cost":
+		runCostCommand(flag.Args()[1:])
+		return
+	case "check":
+		runCheckCommand()
+		return
+	case "template":
+		runTemplateCommand(flag.Args()[1:])
+		return
+	case "list":
+		runListCommand(flag.Args()[1:])
+		return
 	}
-	instanceBaseSection = cfg.Section("INSTANCE")
 
-	bot, err = tgbotapi.NewBotAPI(token)
+	bot, err = tgbotapi.NewBotAPI(getConfig().token)
 	if err != nil {
 		log.Panic(err)
 	}
 
 	log.Printf("Authorized on account %s", bot.Self.UserName)
 
+	// 账号检查较慢，在后台执行，不影响机器人响应消息
+	if cfg.Section(ini.DefaultSection).Key("startupCheck").MustBool(true) {
+		go startupCheck()
+	}
+	go runBudgetChecker()
+	go watchConfigFile()
+	go watchReloadSignal()
+
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 
 	updates := bot.GetUpdatesChan(u)
 
-	for update := range updates {
-		if update.CallbackQuery != nil {
-			handleCallback(update.CallbackQuery)
-		} else if update.Message != nil {
-			handleMessage(update.Message)
+	// 重新加载配置和处理消息在同一个循环中执行，避免处理消息时配置被替换
+	for {
+		select {
+		case update := <-updates:
+			if update.CallbackQuery != nil {
+				handleCallback(update.CallbackQuery)
+			} else if update.Message != nil {
+				handleMessage(update.Message)
+			}
+		case source := <-reloadRequests:
+			reloadConfigNotify(0, source)
 		}
 	}
 }
 
+// 解析配置并替换当前配置。配置无效时返回错误，当前配置保持不变
+func loadConfig(cfg *ini.File) error {
+	err := decryptConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("解密配置失败: %v", err)
+	}
+	var sections []*ini.Section
+	for _, sec := range cfg.Sections() {
+		if len(sec.ParentKeys()) == 0 && isOracleSection(sec) {
+			sections = append(sections, sec)
+		}
+	}
+	if len(sections) == 0 {
+		return errors.New("未找到正确的配置信息, 请参考链接文档配置相关信息。链接: https://github.com/lemoex/oci-help")
+	}
+
+	defSec := cfg.Section(ini.DefaultSection)
+	c := &appConfig{
+		oracleSections:      sections,
+		instanceBaseSection: cfg.Section("INSTANCE"),
+		proxy:               defSec.Key("proxy").Value(),
+		token:               defSec.Key("token").Value(),
+		chatId:              defSec.Key("chat_id").Value(),
+		cmd:                 defSec.Key("cmd").Value(),
+		each:                true,
+		watchConfig:         defSec.Key("watchConfig").MustBool(true),
+	}
+	if defSec.HasKey("EACH") {
+		c.each, _ = defSec.Key("EACH").Bool()
+	}
+	loadBudgetConfig(c, defSec)
+	loadRateLimitConfig(c, defSec)
+	if err := loadTagsConfig(c, defSec); err != nil {
+		return err
+	}
+	currentConfig.Store(c)
+	return nil
+}
+
 func handleMessage(message *tgbotapi.Message) {
 	if message.IsCommand() {
 		switch message.Command() {
 		case "start":
 			sendMainMenu(message.Chat.ID)
+		case "reload":
+			reloadConfigNotify(message.Chat.ID, "/reload")
 		default:
 			msg := tgbotapi.NewMessage(message.Chat.ID, "未知命令，请使用 /start 开始")
 			bot.Send(msg)
@@ -178,6 +250,22 @@ func handleMessage(message *tgbotapi.Message) {
 			switch state.Action {
 			case "resizing_boot_volume":
 				handleResizeBootVolume(message.Chat.ID, state.InstanceIndex, message.Text)
+			case "custom_backup_policy":
+				handleCustomBackupPolicy(message.Chat.ID, state.InstanceIndex, message.Text)
+			case "custom_cost_range":
+				handleCustomCostRange(message.Chat.ID, message.Text)
+			case "creating_block_volume":
+				handleCreateBlockVolume(message.Chat.ID, state.InstanceIndex, message.Text)
+			case "resizing_block_volume":
+				handleResizeBlockVolume(message.Chat.ID, state.InstanceIndex, message.Text)
+			case "editing_template":
+				handleEditTemplate(message.Chat.ID, state.InstanceIndex, state.Data, message.Text)
+			case "creating_template":
+				handleCreateTemplate(message.Chat.ID, state.InstanceIndex, message.Text)
+			case "cloning_template":
+				handleCloneTemplate(message.Chat.ID, state.InstanceIndex, message.Text)
+			case "setting_list_filter":
+				handleListFilter(message.Chat.ID, state.Data, message.Text)
 			}
 			clearUserState(message.Chat.ID)
 		}
@@ -191,11 +279,7 @@ func handleResizeBootVolume(chatID int64, volumeIndex int, sizeText string) {
 		return
 	}
 
-	var bootVolumes []core.BootVolume
-	for _, ad := range availabilityDomains {
-		volumes, _ := getBootVolumes(ad.Name)
-		bootVolumes = append(bootVolumes, volumes...)
-	}
+	bootVolumes := getAllBootVolumes()
 
 	if volumeIndex < 0 || volumeIndex >= len(bootVolumes) {
 		sendErrorMessage(chatID, "无效的引导卷索引")
@@ -235,9 +319,21 @@ func setUserState(chatID int64, action string, instanceIndex int) {
 	}
 }
 
+// 设置用户状态并保存附加数据，例如正在修改的模板参数名称
+func setUserStateData(chatID int64, action string, instanceIndex int, data string) {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+	userStates[chatID] = UserState{
+		Action:        action,
+		InstanceIndex: instanceIndex,
+		Data:          data,
+	}
+}
+
 type UserState struct {
 	Action        string // 例如 "renaming", "upgrading"
 	InstanceIndex int
+	Data          string
 }
 
 var (
@@ -257,6 +353,13 @@ func clearUserState(chatID int64) {
 	defer stateMutex.Unlock()
 	delete(userStates, chatID)
 }
+
+// 清除所有用户的状态，当前账号被取消选择后未完成的输入不再有效
+func clearAllUserStates() {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+	userStates = make(map[int64]UserState)
+}
 func getCurrentUpgradingInstanceIndex(chatID int64) int {
 	state, exists := getUserState(chatID)
 	if !exists || state.Action != "upgrading" {
@@ -270,7 +373,7 @@ func getInstanceCopy() Instance {
 	return instance
 }
 func terminateInstanceAction(chatID int64, instanceIndex int) {
-	instances, _, err := ListInstances(ctx, computeClient, nil)
+	instances, err := ListInstances(ctx, computeClient)
 	if err != nil || instanceIndex >= len(instances) {
 		sendErrorMessage(chatID, "获取实例信息失败或实例索引无效")
 		return
@@ -287,7 +390,7 @@ func terminateInstanceAction(chatID int64, instanceIndex int) {
 }
 
 func changePublicIpAction(chatID int64, instanceIndex int) {
-	instances, _, err := ListInstances(ctx, computeClient, nil)
+	instances, err := ListInstances(ctx, computeClient)
 	if err != nil || instanceIndex >= len(instances) {
 		sendErrorMessage(chatID, "获取实例信息失败或实例索引无效")
 		return
@@ -310,7 +413,7 @@ func changePublicIpAction(chatID int64, instanceIndex int) {
 }
 
 func configureAgentAction(chatID int64, instanceIndex int, action string) {
-	instances, _, err := ListInstances(ctx, computeClient, nil)
+	instances, err := ListInstances(ctx, computeClient)
 	if err != nil || instanceIndex >= len(instances) {
 		sendErrorMessage(chatID, "获取实例信息失败或实例索引无效")
 		return
@@ -346,6 +449,13 @@ func handleCallback(callback *tgbotapi.CallbackQuery) {
 	data := callback.Data
 	chatID := callback.Message.Chat.ID
 
+	// 没有选择账号时 (例如重新加载配置后当前账号被取消选择)，旧消息中的按钮不能再操作原来的账号
+	if oracleSection == nil && !isAccountIndependentCallback(data) {
+		bot.Send(tgbotapi.NewMessage(chatID, "请先选择账号"))
+		sendAccountList(chatID)
+		return
+	}
+
 	// 处理特定前缀的回调
 	if handled := handlePrefixedCallbacks(data, chatID); handled {
 		return
@@ -357,37 +467,62 @@ func handleCallback(callback *tgbotapi.CallbackQuery) {
 		sendAccountList(chatID)
 	case "main_menu":
 		sendMainMenu(chatID)
+	case "all_accounts_overview":
+		viewAllAccountsOverviewTelegram(chatID)
+	case "account_menu":
+		sendAccountMenu(chatID)
+	case "toggle_compartment_recursive":
+		toggleCompartmentRecursive(chatID)
 	case "confirm_create_instance":
 		startCreateInstance(chatID)
 	default:
 		handleRemainingCallbacks(data, chatID)
 	}
 }
-func handleDetachBootVolume(chatID int64, volumeIndex int) {
-	var bootVolumes []core.BootVolume
-	for _, ad := range availabilityDomains {
-		volumes, _ := getBootVolumes(ad.Name)
-		bootVolumes = append(bootVolumes, volumes...)
+
+// 不需要选择账号的回调
+func isAccountIndependentCallback(data string) bool {
+	switch data {
+	case "list_accounts", "main_menu", "all_accounts_overview":
+		return true
 	}
+	return strings.HasPrefix(data, "select_account:")
+}
 
+func handleDetachBootVolume(chatID int64, volumeIndex int) {
+	bootVolumes := getAllBootVolumes()
 	if volumeIndex < 0 || volumeIndex >= len(bootVolumes) {
 		sendErrorMessage(chatID, "无效的引导卷索引")
 		return
 	}
 
 	volume := bootVolumes[volumeIndex]
-	attachments, err := listBootVolumeAttachments(volume.AvailabilityDomain, volume.CompartmentId, volume.Id)
+	attachments, err := listBootVolumeAttachments(computeClient, volume.AvailabilityDomain, volume.CompartmentId, volume.Id)
 	if err != nil {
 		sendErrorMessage(chatID, "获取引导卷附件失败: "+err.Error())
 		return
 	}
+	if len(attachments) == 0 {
+		sendErrorMessage(chatID, "该引导卷未附加到任何实例")
+		return
+	}
 
 	for _, attachment := range attachments {
-		_, err := detachBootVolume(attachment.Id)
+		// 只能从已停止的实例分离引导卷
+		ins, err := getInstance(computeClient, attachment.InstanceId)
+		if err != nil {
+			sendErrorMessage(chatID, "获取实例信息失败: "+err.Error())
+			continue
+		}
+		if ins.LifecycleState != core.InstanceLifecycleStateStopped {
+			sendErrorMessage(chatID, fmt.Sprintf("实例 '%s' 当前状态为 %s，请先停止实例再分离引导卷", *ins.DisplayName, getInstanceState(ins.LifecycleState)))
+			continue
+		}
+		_, err = detachBootVolume(computeClient, attachment.Id)
 		if err != nil {
 			sendErrorMessage(chatID, "分离引导卷失败: "+err.Error())
 		} else {
-			msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("已成功分离引导卷 '%s'", *volume.DisplayName))
+			msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("已成功从实例 '%s' 分离引导卷 '%s'", *ins.DisplayName, *volume.DisplayName))
 			bot.Send(msg)
 		}
 	}
@@ -397,13 +532,220 @@ func handleDetachBootVolume(chatID int64, volumeIndex int) {
 
 }
 
-func handleTerminateBootVolume(chatID int64, volumeIndex int) {
-	var bootVolumes []core.BootVolume
-	for _, ad := range availabilityDomains {
-		volumes, _ := getBootVolumes(ad.Name)
-		bootVolumes = append(bootVolumes, volumes...)
+// 列出可以附加引导卷的实例：与引导卷位于同一可用性域、已停止且没有附加引导卷
+func selectBootVolumeAttachInstance(chatID int64, volumeIndex int, volume core.BootVolume) {
+	instances, err := ListInstances(ctx, computeClient)
+	if err != nil {
+		sendErrorMessage(chatID, "获取实例失败: "+err.Error())
+		return
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for i, ins := range instances {
+		if *ins.AvailabilityDomain != *volume.AvailabilityDomain || ins.LifecycleState != core.InstanceLifecycleStateStopped {
+			continue
+		}
+		attachments, err := listInstanceBootVolumeAttachments(computeClient, ins)
+		if err != nil || len(attachments) > 0 {
+			continue
+		}
+		button := tgbotapi.NewInlineKeyboardButtonData(*ins.DisplayName, fmt.Sprintf("attach_boot_volume:%d:%d", volumeIndex, i))
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(button))
+	}
+	if len(keyboard) == 0 {
+		sendErrorMessage(chatID, "没有可附加的实例。实例需与引导卷位于同一可用性域、已停止且已分离原引导卷。如需替换正在使用的引导卷，请在实例详情中使用“替换引导卷”")
+		return
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("返回", fmt.Sprintf("boot_volume_details:%d", volumeIndex)),
+	))
+	msg := tgbotapi.NewMessage(chatID, "请选择要附加到的实例：")
+	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	bot.Send(msg)
+}
+
+func handleAttachBootVolume(chatID int64, volumeIndex, instanceIndex int) {
+	bootVolumes := getAllBootVolumes()
+	if volumeIndex < 0 || volumeIndex >= len(bootVolumes) {
+		sendErrorMessage(chatID, "无效的引导卷索引")
+		return
+	}
+	instances, err := ListInstances(ctx, computeClient)
+	if err != nil || instanceIndex < 0 || instanceIndex >= len(instances) {
+		sendErrorMessage(chatID, "获取实例信息失败或实例索引无效")
+		return
+	}
+	volume := bootVolumes[volumeIndex]
+	ins := instances[instanceIndex]
+
+	_, err = attachBootVolume(computeClient, ins.Id, volume.Id)
+	if err != nil {
+		sendErrorMessage(chatID, "附加引导卷失败: "+err.Error())
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("正在将引导卷 '%s' 附加到实例 '%s'，附加完成后即可启动实例", *volume.DisplayName, *ins.DisplayName))
+	bot.Send(msg)
+}
+
+// 列出可用于替换的引导卷：与实例位于同一可用性域、可用且未附加到任何实例
+func selectReplacementBootVolume(chatID int64, instanceIndex int) {
+	instances, err := ListInstances(ctx, computeClient)
+	if err != nil || instanceIndex < 0 || instanceIndex >= len(instances) {
+		sendErrorMessage(chatID, "获取实例信息失败或实例索引无效")
+		return
+	}
+	ins := instances[instanceIndex]
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for i, volume := range getAllBootVolumes() {
+		if *volume.AvailabilityDomain != *ins.AvailabilityDomain || volume.LifecycleState != core.BootVolumeLifecycleStateAvailable {
+			continue
+		}
+		attachments, err := listBootVolumeAttachments(computeClient, volume.AvailabilityDomain, volume.CompartmentId, volume.Id)
+		if err != nil || len(attachments) > 0 {
+			continue
+		}
+		button := tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("%s (%d GB)", *volume.DisplayName, *volume.SizeInGBs),
+			fmt.Sprintf("replace_boot_volume:%d:%d", instanceIndex, i))
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(button))
+	}
+	if len(keyboard) == 0 {
+		sendErrorMessage(chatID, "可用性域 "+*ins.AvailabilityDomain+" 中没有可用于替换的引导卷，可以先从备份恢复一个引导卷")
+		return
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("返回", fmt.Sprintf("instance_details:%d", instanceIndex)),
+	))
+	msg := tgbotapi.NewMessage(chatID, "请选择新的引导卷：")
+	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	bot.Send(msg)
+}
+
+func confirmReplaceBootVolume(chatID int64, instanceIndex, volumeIndex int) {
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("确认替换", fmt.Sprintf("confirm_replace_boot_volume:%d:%d", instanceIndex, volumeIndex)),
+			tgbotapi.NewInlineKeyboardButtonData("取消", fmt.Sprintf("instance_details:%d", instanceIndex)),
+		),
+	)
+	msg := tgbotapi.NewMessage(chatID, "替换引导卷将停止实例、分离当前引导卷（保留不删除）、附加新的引导卷并重新启动实例。\n确定要替换吗？")
+	msg.ReplyMarkup = keyboard
+	bot.Send(msg)
+}
+
+func handleReplaceBootVolume(chatID int64, instanceIndex, volumeIndex int) {
+	instances, err := ListInstances(ctx, computeClient)
+	if err != nil || instanceIndex < 0 || instanceIndex >= len(instances) {
+		sendErrorMessage(chatID, "获取实例信息失败或实例索引无效")
+		return
+	}
+	bootVolumes := getAllBootVolumes()
+	if volumeIndex < 0 || volumeIndex >= len(bootVolumes) {
+		sendErrorMessage(chatID, "无效的引导卷索引")
+		return
+	}
+	ins := instances[instanceIndex]
+	volume := bootVolumes[volumeIndex]
+
+	msg := tgbotapi.NewMessage(chatID, "正在替换引导卷...")
+	sentMsg, _ := bot.Send(msg)
+	progress := func(text string) {
+		editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, fmt.Sprintf("替换实例 '%s' 的引导卷为 '%s'\n\n%s", *ins.DisplayName, *volume.DisplayName, text))
+		bot.Send(editMsg)
+	}
+
+	// 整个过程需要几分钟，在后台执行，避免阻塞其他操作
+	s := currentSession()
+	go func() {
+		err := replaceBootVolume(s, ins, volume.Id, progress)
+		if err != nil {
+			progress("❌ 替换引导卷失败: " + err.Error())
+			return
+		}
+		progress("✅ 替换引导卷成功，实例正在启动")
+	}()
+}
+
+// 停止实例 -> 分离当前引导卷 -> 附加新引导卷 -> 启动实例。
+// 分离原引导卷后附加新引导卷失败时，重新附加原引导卷，避免实例没有引导卷
+func replaceBootVolume(s *session, ins core.Instance, bootVolumeId *string, progress func(string)) error {
+	if ins.LifecycleState != core.InstanceLifecycleStateStopped {
+		progress("正在停止实例...")
+		_, err := instanceAction(s.compute, ins.Id, core.InstanceActionActionStop)
+		if err != nil {
+			return fmt.Errorf("停止实例失败: %v", err)
+		}
+		err = waitInstanceState(s.compute, ins.Id, core.InstanceLifecycleStateStopped)
+		if err != nil {
+			return err
+		}
+	}
+
+	attachments, err := listInstanceBootVolumeAttachments(s.compute, ins)
+	if err != nil {
+		return fmt.Errorf("获取引导卷附件失败: %v", err)
+	}
+	var originalId *string
+	for _, attachment := range attachments {
+		progress("正在分离当前引导卷...")
+		_, err = detachBootVolume(s.compute, attachment.Id)
+		if err != nil {
+			return fmt.Errorf("分离引导卷失败: %v", err)
+		}
+		err = waitBootVolumeAttachmentState(s.compute, attachment.Id, core.BootVolumeAttachmentLifecycleStateDetached)
+		if err != nil {
+			return fmt.Errorf("%v\n原引导卷 OCID: %s", err, *attachment.BootVolumeId)
+		}
+		originalId = attachment.BootVolumeId
 	}
 
+	progress("正在附加新的引导卷...")
+	attachment, err := attachBootVolume(s.compute, ins.Id, bootVolumeId)
+	if err != nil {
+		return restoreBootVolume(s, ins, originalId, nil, fmt.Errorf("附加引导卷失败: %v", err), progress)
+	}
+	err = waitBootVolumeAttachmentState(s.compute, attachment.Id, core.BootVolumeAttachmentLifecycleStateAttached)
+	if err != nil {
+		return restoreBootVolume(s, ins, originalId, attachment.Id, err, progress)
+	}
+
+	progress("正在启动实例...")
+	_, err = instanceAction(s.compute, ins.Id, core.InstanceActionActionStart)
+	if err != nil {
+		return fmt.Errorf("启动实例失败: %v", err)
+	}
+	return nil
+}
+
+// 新引导卷附加失败后分离新引导卷的附件并重新附加原引导卷，返回包含处理结果的错误。
+// 重新附加失败时在错误中给出原引导卷的 OCID，便于手动附加
+func restoreBootVolume(s *session, ins core.Instance, originalId, failedAttachmentId *string, cause error, progress func(string)) error {
+	if originalId == nil {
+		return cause
+	}
+	progress("附加新的引导卷失败，正在重新附加原引导卷...")
+	var err error
+	if failedAttachmentId != nil {
+		// 附件可能已经变为已分离，分离请求失败时仍以最终状态为准
+		detachBootVolume(s.compute, failedAttachmentId)
+		err = waitBootVolumeAttachmentState(s.compute, failedAttachmentId, core.BootVolumeAttachmentLifecycleStateDetached)
+	}
+	if err == nil {
+		var attachment core.BootVolumeAttachment
+		if attachment, err = attachBootVolume(s.compute, ins.Id, originalId); err == nil {
+			err = waitBootVolumeAttachmentState(s.compute, attachment.Id, core.BootVolumeAttachmentLifecycleStateAttached)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%v\n重新附加原引导卷失败: %v\n实例已停止且没有引导卷，请手动附加原引导卷, OCID: %s", cause, err, *originalId)
+	}
+	return fmt.Errorf("%v\n已重新附加原引导卷，实例保持停止状态", cause)
+}
+
+func handleTerminateBootVolume(chatID int64, volumeIndex int) {
+	bootVolumes := getAllBootVolumes()
+
 	if volumeIndex < 0 || volumeIndex >= len(bootVolumes) {
 		sendErrorMessage(chatID, "无效的引导卷索引")
 		return
@@ -422,11 +764,7 @@ func handleTerminateBootVolume(chatID int64, volumeIndex int) {
 	manageBootVolumesTelegram(chatID)
 }
 func handleBootVolumePerformance(chatID int64, volumeIndex int, performance int64) {
-	var bootVolumes []core.BootVolume
-	for _, ad := range availabilityDomains {
-		volumes, _ := getBootVolumes(ad.Name)
-		bootVolumes = append(bootVolumes, volumes...)
-	}
+	bootVolumes := getAllBootVolumes()
 
 	if volumeIndex < 0 || volumeIndex >= len(bootVolumes) {
 		sendErrorMessage(chatID, "无效的引导卷索引")
@@ -477,9 +815,109 @@ func handlePrefixedCallbacks(data string, chatID int64) bool {
 			performance, _ := strconv.ParseInt(parts[2], 10, 64)
 			handleBootVolumePerformance(chatID, volumeIndex, performance)
 		}
+	case strings.HasPrefix(data, "confirm_detach_boot_volume:"):
+		volumeIndex, _ := strconv.Atoi(strings.TrimPrefix(data, "confirm_detach_boot_volume:"))
+		handleDetachBootVolume(chatID, volumeIndex)
+	case strings.HasPrefix(data, "attach_boot_volume:"):
+		parts := strings.Split(data, ":")
+		if len(parts) == 3 {
+			volumeIndex, _ := strconv.Atoi(parts[1])
+			instanceIndex, _ := strconv.Atoi(parts[2])
+			handleAttachBootVolume(chatID, volumeIndex, instanceIndex)
+		}
+	case strings.HasPrefix(data, "replace_boot_volume:"):
+		parts := strings.Split(data, ":")
+		if len(parts) == 3 {
+			instanceIndex, _ := strconv.Atoi(parts[1])
+			volumeIndex, _ := strconv.Atoi(parts[2])
+			confirmReplaceBootVolume(chatID, instanceIndex, volumeIndex)
+		}
+	case strings.HasPrefix(data, "confirm_replace_boot_volume:"):
+		parts := strings.Split(data, ":")
+		if len(parts) == 3 {
+			instanceIndex, _ := strconv.Atoi(parts[1])
+			volumeIndex, _ := strconv.Atoi(parts[2])
+			handleReplaceBootVolume(chatID, instanceIndex, volumeIndex)
+		}
 	case strings.HasPrefix(data, "confirm_terminate_boot_volume:"):
 		volumeIndex, _ := strconv.Atoi(strings.TrimPrefix(data, "confirm_terminate_boot_volume:"))
 		handleTerminateBootVolume(chatID, volumeIndex)
+	case strings.HasPrefix(data, "create_boot_volume_backup:"):
+		parts := strings.Split(data, ":")
+		if len(parts) == 3 {
+			volumeIndex, _ := strconv.Atoi(parts[1])
+			handleCreateBootVolumeBackup(chatID, volumeIndex, parts[2])
+		}
+	case strings.HasPrefix(data, "restore_boot_volume_backup:"):
+		parts := strings.Split(data, ":")
+		if len(parts) == 3 {
+			backupKey, _ := strconv.Atoi(parts[1])
+			adIndex, _ := strconv.Atoi(parts[2])
+			handleRestoreBootVolumeBackup(chatID, backupKey, adIndex)
+		}
+	case strings.HasPrefix(data, "confirm_delete_boot_volume_backup:"):
+		backupKey, _ := strconv.Atoi(strings.TrimPrefix(data, "confirm_delete_boot_volume_backup:"))
+		handleDeleteBootVolumeBackup(chatID, backupKey)
+	case strings.HasPrefix(data, "assign_backup_policy:"):
+		parts := strings.Split(data, ":")
+		if len(parts) == 3 {
+			volumeIndex, _ := strconv.Atoi(parts[1])
+			policyIndex, _ := strconv.Atoi(parts[2])
+			handleAssignBackupPolicy(chatID, volumeIndex, policyIndex)
+		}
+	case strings.HasPrefix(data, "unassign_backup_policy:"):
+		volumeIndex, _ := strconv.Atoi(strings.TrimPrefix(data, "unassign_backup_policy:"))
+		handleUnassignBackupPolicy(chatID, volumeIndex)
+	case strings.HasPrefix(data, "custom_backup_policy:"):
+		volumeIndex, _ := strconv.Atoi(strings.TrimPrefix(data, "custom_backup_policy:"))
+		promptCustomBackupPolicy(chatID, volumeIndex)
+	case strings.HasPrefix(data, "block_volume_performance:"):
+		parts := strings.Split(data, ":")
+		if len(parts) == 3 {
+			volumeKey, _ := strconv.Atoi(parts[1])
+			performance, _ := strconv.ParseInt(parts[2], 10, 64)
+			handleBlockVolumePerformance(chatID, volumeKey, performance)
+		}
+	case strings.HasPrefix(data, "confirm_delete_block_volume:"):
+		volumeKey, _ := strconv.Atoi(strings.TrimPrefix(data, "confirm_delete_block_volume:"))
+		handleDeleteBlockVolume(chatID, volumeKey)
+	case strings.HasPrefix(data, "confirm_detach_block_volume:"):
+		volumeKey, _ := strconv.Atoi(strings.TrimPrefix(data, "confirm_detach_block_volume:"))
+		handleDetachBlockVolume(chatID, volumeKey)
+	case strings.HasPrefix(data, "attach_block_volume_instance:"):
+		parts := strings.Split(data, ":")
+		if len(parts) == 3 {
+			volumeKey, _ := strconv.Atoi(parts[1])
+			instanceKey, _ := strconv.Atoi(parts[2])
+			selectBlockVolumeAttachType(chatID, volumeKey, instanceKey)
+		}
+	case strings.HasPrefix(data, "attach_block_volume:"):
+		parts := strings.Split(data, ":")
+		if len(parts) == 4 {
+			volumeKey, _ := strconv.Atoi(parts[1])
+			instanceKey, _ := strconv.Atoi(parts[2])
+			handleAttachBlockVolume(chatID, volumeKey, instanceKey, parts[3])
+		}
+	case strings.HasPrefix(data, "clone_boot_volume:"):
+		parts := strings.Split(data, ":")
+		if len(parts) == 3 {
+			volumeIndex, _ := strconv.Atoi(parts[1])
+			adIndex, _ := strconv.Atoi(parts[2])
+			handleCloneBootVolume(chatID, volumeIndex, adIndex)
+		}
+	case strings.HasPrefix(data, "clone_result:"):
+		parts := strings.Split(data, ":")
+		if len(parts) == 3 {
+			key, _ := strconv.Atoi(parts[1])
+			handleCloneResult(chatID, key, parts[2])
+		}
+	case strings.HasPrefix(data, "launch_boot_volume:"):
+		parts := strings.Split(data, ":")
+		if len(parts) == 3 {
+			volumeIndex, _ := strconv.Atoi(parts[1])
+			templateIndex, _ := strconv.Atoi(parts[2])
+			confirmLaunchFromBootVolume(chatID, volumeIndex, templateIndex)
+		}
 	default:
 		return false
 	}
@@ -494,6 +932,12 @@ func handleRemainingCallbacks(data string, chatID int64) {
 	case strings.HasPrefix(data, "account_action:"):
 		action := strings.TrimPrefix(data, "account_action:")
 		handleAccountAction(chatID, action)
+	case strings.HasPrefix(data, "select_region:"):
+		regionIndex, _ := strconv.Atoi(strings.TrimPrefix(data, "select_region:"))
+		selectRegion(chatID, regionIndex)
+	case strings.HasPrefix(data, "select_compartment:"):
+		index, _ := strconv.Atoi(strings.TrimPrefix(data, "select_compartment:"))
+		selectCompartment(chatID, index)
 	case strings.HasPrefix(data, "instance_details:"):
 		instanceIndex, _ := strconv.Atoi(strings.TrimPrefix(data, "instance_details:"))
 		showInstanceDetails(chatID, instanceIndex)
@@ -507,81 +951,87 @@ func handleRemainingCallbacks(data string, chatID int64) {
 			action := parts[2]
 			handleBootVolumeAction(chatID, volumeIndex, action)
 		}
+	case strings.HasPrefix(data, "cost_period:"):
+		selectCostGroupBy(chatID, strings.TrimPrefix(data, "cost_period:"))
+	case strings.HasPrefix(data, "cost_report:"):
+		parts := strings.Split(data, ":")
+		if len(parts) == 4 {
+			sendCostReportTelegram(chatID, parts[1], parts[2], parts[3])
+		}
+	case strings.HasPrefix(data, "block_volume_details:"):
+		volumeKey, _ := strconv.Atoi(strings.TrimPrefix(data, "block_volume_details:"))
+		showBlockVolumeDetails(chatID, volumeKey)
+	case strings.HasPrefix(data, "block_volume_action:"):
+		parts := strings.Split(data, ":")
+		if len(parts) == 3 {
+			volumeKey, _ := strconv.Atoi(parts[1])
+			handleBlockVolumeAction(chatID, volumeKey, parts[2])
+		}
+	case strings.HasPrefix(data, "create_block_volume:"):
+		adIndex, _ := strconv.Atoi(strings.TrimPrefix(data, "create_block_volume:"))
+		promptBlockVolumeSize(chatID, adIndex)
+	case strings.HasPrefix(data, "template_details:"):
+		index, _ := strconv.Atoi(strings.TrimPrefix(data, "template_details:"))
+		showTemplateDetails(chatID, index)
+	case strings.HasPrefix(data, "template_action:"):
+		parts := strings.Split(data, ":")
+		if len(parts) == 3 {
+			index, _ := strconv.Atoi(parts[1])
+			handleTemplateAction(chatID, index, parts[2])
+		}
+	case strings.HasPrefix(data, "template_edit:"):
+		parts := strings.Split(data, ":")
+		if len(parts) == 3 {
+			index, _ := strconv.Atoi(parts[1])
+			promptEditTemplate(chatID, index, parts[2])
+		}
+	case strings.HasPrefix(data, "confirm_delete_template:"):
+		index, _ := strconv.Atoi(strings.TrimPrefix(data, "confirm_delete_template:"))
+		handleDeleteTemplate(chatID, index)
+	case strings.HasPrefix(data, "list_page:"):
+		parts := strings.Split(data, ":")
+		if len(parts) == 3 {
+			page, _ := strconv.Atoi(parts[2])
+			showListPage(chatID, parts[1], page)
+		}
+	case strings.HasPrefix(data, "list_refresh:"):
+		refreshList(chatID, strings.TrimPrefix(data, "list_refresh:"))
+	case strings.HasPrefix(data, "list_filter:"):
+		showListFilterMenu(chatID, strings.TrimPrefix(data, "list_filter:"))
+	case strings.HasPrefix(data, "list_filter_set:"):
+		parts := strings.Split(data, ":")
+		if len(parts) == 3 {
+			promptListFilter(chatID, parts[1], parts[2])
+		}
+	case strings.HasPrefix(data, "list_sort:"):
+		parts := strings.Split(data, ":")
+		if len(parts) == 3 {
+			setListSort(chatID, parts[1], parts[2])
+		}
+	case strings.HasPrefix(data, "list_filter_terminated:"):
+		toggleListTerminated(chatID, strings.TrimPrefix(data, "list_filter_terminated:"))
+	case strings.HasPrefix(data, "list_filter_clear:"):
+		clearListFilter(chatID, strings.TrimPrefix(data, "list_filter_clear:"))
+	case strings.HasPrefix(data, "tag_filter_own:"):
+		toggleOwnTagFilter(chatID, strings.TrimPrefix(data, "tag_filter_own:"))
+	case strings.HasPrefix(data, "new_template:"):
+		scope, _ := strconv.Atoi(strings.TrimPrefix(data, "new_template:"))
+		promptCreateTemplate(chatID, scope)
+	case strings.HasPrefix(data, "boot_volume_backup_details:"):
+		backupKey, _ := strconv.Atoi(strings.TrimPrefix(data, "boot_volume_backup_details:"))
+		showBootVolumeBackupDetails(chatID, backupKey)
+	case strings.HasPrefix(data, "boot_volume_backup_action:"):
+		parts := strings.Split(data, ":")
+		if len(parts) == 3 {
+			backupKey, _ := strconv.Atoi(parts[1])
+			handleBootVolumeBackupAction(chatID, backupKey, parts[2])
+		}
 	default:
 		log.Printf("未知的回调数据: %s", data)
 	}
 }
-func viewCostTelegram(chatID int64) {
-	msg := tgbotapi.NewMessage(chatID, "正在获取成本数据...")
-	sentMsg, _ := bot.Send(msg)
-
-	usageapiClient, err := usageapi.NewUsageapiClientWithConfigurationProvider(provider)
-	if err != nil {
-		editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, "创建 UsageapiClient 失败: "+err.Error())
-		bot.Send(editMsg)
-		return
-	}
-
-	firstDay, lastDay := currMouthFirstLastDay()
-	tenancyOCID, err := provider.TenancyOCID()
-	if err != nil {
-		editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, "获取 Tenancy OCID 失败: "+err.Error())
-		bot.Send(editMsg)
-		return
-	}
-
-	req := usageapi.RequestSummarizedUsagesRequest{
-		RequestSummarizedUsagesDetails: usageapi.RequestSummarizedUsagesDetails{
-			CompartmentDepth: common.Float32(6),
-			Granularity:      usageapi.RequestSummarizedUsagesDetailsGranularityMonthly,
-			GroupBy:          []string{"service"},
-			QueryType:        usageapi.RequestSummarizedUsagesDetailsQueryTypeUsage,
-			TenantId:         &tenancyOCID,
-			TimeUsageStarted: &common.SDKTime{Time: firstDay},
-			TimeUsageEnded:   &common.SDKTime{Time: lastDay},
-		},
-	}
-
-	resp, err := usageapiClient.RequestSummarizedUsages(context.Background(), req)
-	if err != nil {
-		editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, "获取成本数据失败: "+err.Error())
-		bot.Send(editMsg)
-		return
-	}
-
-	var messageText strings.Builder
-	messageText.WriteString("本月成本概览：\n\n")
-
-	var totalCost float32
-	for _, item := range resp.Items {
-		if item.Service == nil || item.Unit == nil || item.ComputedAmount == nil || item.ComputedQuantity == nil {
-			continue // 跳过无效的数据
-		}
-		cost := *item.ComputedAmount
-		totalCost += cost
-		messageText.WriteString(fmt.Sprintf("[服务: %s] 单位: %s 费用: %.2f 使用量: %.2f\n",
-			*item.Service, *item.Unit, *item.ComputedAmount, *item.ComputedQuantity))
-	}
-
-	messageText.WriteString(fmt.Sprintf("\n总成本: %.2f\n", totalCost))
-
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("返回", "select_account:"+strconv.Itoa(getCurrentAccountIndex())),
-		),
-	)
-
-	editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, messageText.String())
-	editMsg.ReplyMarkup = &keyboard
-	bot.Send(editMsg)
-}
-
 func showBootVolumeDetails(chatID int64, volumeIndex int) {
-	var bootVolumes []core.BootVolume
-	for _, ad := range availabilityDomains {
-		volumes, _ := getBootVolumes(ad.Name)
-		bootVolumes = append(bootVolumes, volumes...)
-	}
+	bootVolumes := getAllBootVolumes()
 
 	if volumeIndex < 0 || volumeIndex >= len(bootVolumes) {
 		msg := tgbotapi.NewMessage(chatID, "无效的引导卷索引")
@@ -591,10 +1041,10 @@ func showBootVolumeDetails(chatID int64, volumeIndex int) {
 
 	volume := bootVolumes[volumeIndex]
 
-	attachments, _ := listBootVolumeAttachments(volume.AvailabilityDomain, volume.CompartmentId, volume.Id)
+	attachments, _ := listBootVolumeAttachments(computeClient, volume.AvailabilityDomain, volume.CompartmentId, volume.Id)
 	attachIns := make([]string, 0)
 	for _, attachment := range attachments {
-		ins, err := getInstance(attachment.InstanceId)
+		ins, err := getInstance(computeClient, attachment.InstanceId)
 		if err != nil {
 			attachIns = append(attachIns, err.Error())
 		} else {
@@ -621,6 +1071,7 @@ func showBootVolumeDetails(chatID int64, volumeIndex int) {
 	messageText.WriteString(fmt.Sprintf("可用性域: %s\n", *volume.AvailabilityDomain))
 	messageText.WriteString(fmt.Sprintf("性能: %s\n", performance))
 	messageText.WriteString(fmt.Sprintf("附加的实例: %s\n", strings.Join(attachIns, ", ")))
+	messageText.WriteString(fmt.Sprintf("标签: %s\n", formatTags(volume.FreeformTags, volume.DefinedTags)))
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
@@ -628,13 +1079,25 @@ func showBootVolumeDetails(chatID int64, volumeIndex int) {
 			tgbotapi.NewInlineKeyboardButtonData("修改大小", fmt.Sprintf("boot_volume_action:%d:resize", volumeIndex)),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("分离引导卷", fmt.Sprintf("boot_volume_action:%d:detach", volumeIndex)),
-			tgbotapi.NewInlineKeyboardButtonData("终止引导卷", fmt.Sprintf("boot_volume_action:%d:terminate", volumeIndex)),
+			tgbotapi.NewInlineKeyboardButtonData("创建备份", fmt.Sprintf("boot_volume_action:%d:backup", volumeIndex)),
+			tgbotapi.NewInlineKeyboardButtonData("备份策略", fmt.Sprintf("boot_volume_action:%d:backup_policy", volumeIndex)),
+			tgbotapi.NewInlineKeyboardButtonData("克隆", fmt.Sprintf("boot_volume_action:%d:clone", volumeIndex)),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("返回引导卷列表", "account_action:manage_boot_volumes"),
+			tgbotapi.NewInlineKeyboardButtonData("分离引导卷", fmt.Sprintf("boot_volume_action:%d:detach", volumeIndex)),
+			tgbotapi.NewInlineKeyboardButtonData("终止引导卷", fmt.Sprintf("boot_volume_action:%d:terminate", volumeIndex)),
 		),
 	)
+	// 已分离且可用的引导卷可以直接用于创建实例
+	if len(attachments) == 0 && volume.LifecycleState == core.BootVolumeLifecycleStateAvailable {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("从此引导卷创建实例", fmt.Sprintf("boot_volume_action:%d:launch", volumeIndex)),
+			tgbotapi.NewInlineKeyboardButtonData("附加到已停止的实例", fmt.Sprintf("boot_volume_action:%d:attach", volumeIndex)),
+		))
+	}
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("返回引导卷列表", "account_action:manage_boot_volumes"),
+	))
 
 	msg := tgbotapi.NewMessage(chatID, messageText.String())
 	msg.ReplyMarkup = keyboard
@@ -642,11 +1105,7 @@ func showBootVolumeDetails(chatID int64, volumeIndex int) {
 }
 
 func handleBootVolumeAction(chatID int64, volumeIndex int, action string) {
-	var bootVolumes []core.BootVolume
-	for _, ad := range availabilityDomains {
-		volumes, _ := getBootVolumes(ad.Name)
-		bootVolumes = append(bootVolumes, volumes...)
-	}
+	bootVolumes := getAllBootVolumes()
 
 	if volumeIndex < 0 || volumeIndex >= len(bootVolumes) {
 		msg := tgbotapi.NewMessage(chatID, "无效的引导卷索引")
@@ -679,11 +1138,112 @@ func handleBootVolumeAction(chatID int64, volumeIndex int, action string) {
 		confirmDetachBootVolume(chatID, volumeIndex)
 	case "terminate":
 		confirmTerminateBootVolume(chatID, volumeIndex)
+	case "launch":
+		selectBootVolumeLaunchTemplate(chatID, volumeIndex)
+	case "attach":
+		selectBootVolumeAttachInstance(chatID, volumeIndex, volume)
+	case "clone":
+		selectBootVolumeCloneTarget(chatID, volumeIndex, volume)
+	case "backup":
+		promptCreateBootVolumeBackup(chatID, volumeIndex)
+	case "backup_policy":
+		showBootVolumeBackupPolicy(chatID, volumeIndex)
 	default:
 		msg := tgbotapi.NewMessage(chatID, "未知的操作")
 		bot.Send(msg)
 	}
 }
+
+// 选择使用引导卷创建实例时的实例模板（仅使用模板中的配置、网络等参数，系统镜像和引导卷大小以引导卷为准）
+func selectBootVolumeLaunchTemplate(chatID int64, volumeIndex int) {
+	templates, err := getLaunchTemplates()
+	if err != nil {
+		sendErrorMessage(chatID, "解析实例模板失败: "+err.Error())
+		return
+	}
+	if len(templates) == 0 {
+		sendErrorMessage(chatID, "未找到实例模板")
+		return
+	}
+
+	var messageText strings.Builder
+	messageText.WriteString("选择实例模板，将使用该模板的配置从引导卷创建实例：\n\n")
+	messageText.WriteString(fmt.Sprintf("%-5s %-10s %-20s %-10s %-10s\n", "序号", "模板", "配置", "CPU个数", "内存(GB)"))
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for i, t := range templates {
+		cpu := t.Section.Key("cpus").Value()
+		if cpu == "" {
+			cpu = "-"
+		}
+		memory := t.Section.Key("memoryInGBs").Value()
+		if memory == "" {
+			memory = "-"
+		}
+		shape := t.Section.Key("shape").Value()
+		messageText.WriteString(fmt.Sprintf("%-5d %-10s %-20s %-10s %-10s\n", i+1, t.Name, shape, cpu, memory))
+
+		button := tgbotapi.NewInlineKeyboardButtonData(
+			t.DisplayName(),
+			fmt.Sprintf("launch_boot_volume:%d:%d", volumeIndex, i))
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(button))
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("返回", fmt.Sprintf("boot_volume_details:%d", volumeIndex)),
+	))
+
+	msg := tgbotapi.NewMessage(chatID, messageText.String())
+	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	bot.Send(msg)
+}
+
+func confirmLaunchFromBootVolume(chatID int64, volumeIndex, templateIndex int) {
+	bootVolumes := getAllBootVolumes()
+	if volumeIndex < 0 || volumeIndex >= len(bootVolumes) {
+		sendErrorMessage(chatID, "无效的引导卷索引")
+		return
+	}
+	volume := bootVolumes[volumeIndex]
+
+	template, err := getLaunchTemplate(templateIndex)
+	if err != nil {
+		sendErrorMessage(chatID, err.Error())
+		return
+	}
+	newInstance, err := mapTemplateToInstance(template)
+	if err != nil {
+		sendErrorMessage(chatID, err.Error())
+		return
+	}
+	newInstance.BootVolumeId = *volume.Id
+	newInstance.AvailabilityDomain = *volume.AvailabilityDomain
+	newInstance.Sum = 1
+	newInstance.Each = 0
+	updateNewInstance(newInstance)
+
+	messageText := fmt.Sprintf("确认使用引导卷创建以下配置的实例：\n\n"+
+		"模板: %s\n"+
+		"引导卷: %s\n"+
+		"形状: %s\n"+
+		"CPU: %g\n"+
+		"内存: %g GB\n"+
+		"引导卷大小: %d GB\n"+
+		"可用性域: %s\n\n"+
+		"是否确认创建？",
+		template.DisplayName(), *volume.DisplayName, newInstance.Shape, newInstance.Ocpus, newInstance.MemoryInGBs,
+		*volume.SizeInGBs, newInstance.AvailabilityDomain)
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("确认创建", "confirm_create_instance"),
+			tgbotapi.NewInlineKeyboardButtonData("取消", fmt.Sprintf("boot_volume_details:%d", volumeIndex)),
+		),
+	)
+
+	msg := tgbotapi.NewMessage(chatID, messageText)
+	msg.ReplyMarkup = keyboard
+	bot.Send(msg)
+}
 func confirmDetachBootVolume(chatID int64, volumeIndex int) {
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
@@ -691,7 +1251,7 @@ func confirmDetachBootVolume(chatID int64, volumeIndex int) {
 			tgbotapi.NewInlineKeyboardButtonData("取消", fmt.Sprintf("boot_volume_details:%d", volumeIndex)),
 		),
 	)
-	msg := tgbotapi.NewMessage(chatID, "确定要分离此引导卷吗？")
+	msg := tgbotapi.NewMessage(chatID, "确定要分离此引导卷吗？附加的实例需处于停止状态。")
 	msg.ReplyMarkup = keyboard
 	bot.Send(msg)
 }
@@ -711,28 +1271,31 @@ func manageBootVolumesTelegram(chatID int64) {
 	msg := tgbotapi.NewMessage(chatID, "正在获取引导卷数据...")
 	sentMsg, _ := bot.Send(msg)
 
-	var bootVolumes []core.BootVolume
+	// 按可用性域的顺序合并结果，与 getAllBootVolumes 的顺序一致，翻页和引导卷详情使用相同的下标
+	results := make([][]core.BootVolume, len(availabilityDomains))
 	var wg sync.WaitGroup
-	var mu sync.Mutex // 用于保护 bootVolumes 切片
 	errorChan := make(chan error, len(availabilityDomains))
 
-	for _, ad := range availabilityDomains {
+	for i, ad := range availabilityDomains {
 		wg.Add(1)
-		go func(adName *string) {
+		go func(i int, adName *string) {
 			defer wg.Done()
 			volumes, err := getBootVolumes(adName)
 			if err != nil {
 				errorChan <- fmt.Errorf("获取可用性域 %s 的引导卷失败: %v", *adName, err)
 			} else {
-				mu.Lock()
-				bootVolumes = append(bootVolumes, volumes...)
-				mu.Unlock()
+				results[i] = volumes
 			}
-		}(ad.Name)
+		}(i, ad.Name)
 	}
 	wg.Wait()
 	close(errorChan)
 
+	var bootVolumes []core.BootVolume
+	for _, volumes := range results {
+		bootVolumes = append(bootVolumes, volumes...)
+	}
+
 	// 收集所有错误
 	var errorMessages []string
 	for err := range errorChan {
@@ -752,7 +1315,8 @@ func manageBootVolumesTelegram(chatID int64) {
 		// 添加一个返回按钮
 		keyboard := tgbotapi.NewInlineKeyboardMarkup(
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("返回", "select_account:"+strconv.Itoa(getCurrentAccountIndex())),
+				tgbotapi.NewInlineKeyboardButtonData("引导卷备份", "account_action:boot_volume_backups"),
+				tgbotapi.NewInlineKeyboardButtonData("返回", "account_action:manage_storage"),
 			),
 		)
 		editMsg.ReplyMarkup = &keyboard
@@ -765,9 +1329,14 @@ func manageBootVolumesTelegram(chatID int64) {
 	messageText.WriteString(fmt.Sprintf("引导卷 (当前账号: %s)\n\n", oracleSection.Name()))
 	messageText.WriteString(fmt.Sprintf("%-5s %-30s %-15s %-10s\n", "序号", "名称", "状态", "大小(GB)"))
 
-	var keyboard [][]tgbotapi.InlineKeyboardButton
+	indexes := getFilteredIndexes("boot_volumes", bootVolumeListItems(bootVolumes), &messageText)
+	if len(indexes) == 0 {
+		messageText.WriteString("没有符合筛选条件的引导卷\n")
+	}
 
-	for i, volume := range bootVolumes {
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, i := range getPageIndexes("boot_volumes", indexes) {
+		volume := bootVolumes[i]
 		messageText.WriteString(fmt.Sprintf("%-5d %-30s %-15s %-10d\n",
 			i+1,
 			*volume.DisplayName,
@@ -781,17 +1350,21 @@ func manageBootVolumesTelegram(chatID int64) {
 		keyboard = append(keyboard, row)
 	}
 
+	if row := pageButtons("boot_volumes", len(indexes)); row != nil {
+		keyboard = append(keyboard, row)
+	}
+	keyboard = append(keyboard, listFilterButtons("boot_volumes"))
 	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("返回", "select_account:"+strconv.Itoa(getCurrentAccountIndex())),
+		tgbotapi.NewInlineKeyboardButtonData("引导卷备份", "account_action:boot_volume_backups"),
+		tgbotapi.NewInlineKeyboardButtonData("返回", "account_action:manage_storage"),
 	))
 
 	editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, messageText.String())
-	editMsg.ParseMode = "Markdown"
 	editMsg.ReplyMarkup = &tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
 	bot.Send(editMsg)
 }
 func handleInstanceAction(chatID int64, instanceIndex int, action string) {
-	instances, _, err := ListInstances(ctx, computeClient, nil)
+	instances, err := ListInstances(ctx, computeClient)
 	if err != nil || instanceIndex >= len(instances) {
 		sendErrorMessage(chatID, "获取实例信息失败或实例索引无效")
 		return
@@ -801,13 +1374,13 @@ func handleInstanceAction(chatID int64, instanceIndex int, action string) {
 
 	switch action {
 	case "start":
-		_, err := instanceAction(instance.Id, core.InstanceActionActionStart)
+		_, err := instanceAction(computeClient, instance.Id, core.InstanceActionActionStart)
 		sendActionResult(chatID, "启动实例", err)
 	case "stop":
-		_, err := instanceAction(instance.Id, core.InstanceActionActionSoftstop)
+		_, err := instanceAction(computeClient, instance.Id, core.InstanceActionActionSoftstop)
 		sendActionResult(chatID, "停止实例", err)
 	case "reset":
-		_, err := instanceAction(instance.Id, core.InstanceActionActionSoftreset)
+		_, err := instanceAction(computeClient, instance.Id, core.InstanceActionActionSoftreset)
 		sendActionResult(chatID, "重启实例", err)
 	case "terminate":
 		confirmTerminateInstance(chatID, instanceIndex)
@@ -815,6 +1388,8 @@ func handleInstanceAction(chatID int64, instanceIndex int, action string) {
 		confirmChangePublicIp(chatID, instanceIndex)
 	case "agent_config":
 		promptAgentConfig(chatID, instanceIndex)
+	case "replace_boot_volume":
+		selectReplacementBootVolume(chatID, instanceIndex)
 	default:
 		sendErrorMessage(chatID, "未知的实例操作")
 	}
@@ -876,7 +1451,7 @@ func showInstanceDetails(chatID int64, instanceIndex int) {
 	msg := tgbotapi.NewMessage(chatID, "正在获取实例详细信息...")
 	sentMsg, _ := bot.Send(msg)
 
-	instances, _, err := ListInstances(ctx, computeClient, nil)
+	instances, err := ListInstances(ctx, computeClient)
 	if err != nil || instanceIndex >= len(instances) {
 		editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, "获取实例信息失败或实例索引无效")
 		bot.Send(editMsg)
@@ -906,6 +1481,7 @@ func showInstanceDetails(chatID int64, instanceIndex int) {
 	messageText.WriteString(fmt.Sprintf("公共IP: %s\n", strPublicIps))
 	messageText.WriteString(fmt.Sprintf("可用性域: %s\n", *instance.AvailabilityDomain))
 	messageText.WriteString(fmt.Sprintf("配置: %s\n", *instance.Shape))
+	messageText.WriteString(fmt.Sprintf("标签: %s\n", formatTags(instance.FreeformTags, instance.DefinedTags)))
 	messageText.WriteString(fmt.Sprintf("OCPU计数: %g\n", *instance.ShapeConfig.Ocpus))
 	messageText.WriteString(fmt.Sprintf("网络带宽(Gbps): %g\n", *instance.ShapeConfig.NetworkingBandwidthInGbps))
 	messageText.WriteString(fmt.Sprintf("内存(GB): %g\n\n", *instance.ShapeConfig.MemoryInGBs))
@@ -929,6 +1505,7 @@ func showInstanceDetails(chatID int64, instanceIndex int) {
 		},
 		{
 			tgbotapi.NewInlineKeyboardButtonData("Agent插件配置", fmt.Sprintf("instance_action:%d:agent_config", instanceIndex)),
+			tgbotapi.NewInlineKeyboardButtonData("替换引导卷", fmt.Sprintf("instance_action:%d:replace_boot_volume", instanceIndex)),
 		},
 		{
 			tgbotapi.NewInlineKeyboardButtonData("返回实例列表", "account_action:list_instances"),
@@ -958,11 +1535,14 @@ func createInstanceTelegram(chatID int64) {
 		return
 	}
 
-	var instanceSections []*ini.Section
-	instanceSections = append(instanceSections, instanceBaseSection.ChildSections()...)
-	instanceSections = append(instanceSections, oracleSection.ChildSections()...)
+	templates, err := getLaunchTemplates()
+	if err != nil {
+		editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, "解析实例模板失败: "+err.Error())
+		bot.Send(editMsg)
+		return
+	}
 
-	if len(instanceSections) == 0 {
+	if len(templates) == 0 {
 		editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, "未找到实例模板")
 		bot.Send(editMsg)
 		return
@@ -970,25 +1550,25 @@ func createInstanceTelegram(chatID int64) {
 
 	var messageText strings.Builder
 	messageText.WriteString(fmt.Sprintf("选择对应的实例模板开始创建实例 (当前账号: %s)\n\n", oracleSectionName))
-	messageText.WriteString(fmt.Sprintf("%-5s %-20s %-10s %-10s\n", "序号", "配置", "CPU个数", "内存(GB)"))
+	messageText.WriteString(fmt.Sprintf("%-5s %-10s %-20s %-10s %-10s\n", "序号", "模板", "配置", "CPU个数", "内存(GB)"))
 
 	var keyboard [][]tgbotapi.InlineKeyboardButton
 
-	for i, instanceSec := range instanceSections {
-		cpu := instanceSec.Key("cpus").Value()
+	for i, t := range templates {
+		cpu := t.Section.Key("cpus").Value()
 		if cpu == "" {
 			cpu = "-"
 		}
-		memory := instanceSec.Key("memoryInGBs").Value()
+		memory := t.Section.Key("memoryInGBs").Value()
 		if memory == "" {
 			memory = "-"
 		}
-		shape := instanceSec.Key("shape").Value()
+		shape := t.Section.Key("shape").Value()
 
-		messageText.WriteString(fmt.Sprintf("%-5d %-20s %-10s %-10s\n", i+1, shape, cpu, memory))
+		messageText.WriteString(fmt.Sprintf("%-5d %-10s %-20s %-10s %-10s\n", i+1, t.Name, shape, cpu, memory))
 
 		button := tgbotapi.NewInlineKeyboardButtonData(
-			fmt.Sprintf("模板 %d", i+1),
+			t.DisplayName(),
 			fmt.Sprintf("create_instance:%d", i))
 		row := tgbotapi.NewInlineKeyboardRow(button)
 		keyboard = append(keyboard, row)
@@ -1005,21 +1585,16 @@ func createInstanceTelegram(chatID int64) {
 }
 
 func confirmCreateInstance(chatID int64, index int) {
-	var instanceSections []*ini.Section
-	instanceSections = append(instanceSections, instanceBaseSection.ChildSections()...)
-	instanceSections = append(instanceSections, oracleSection.ChildSections()...)
-
-	if index < 0 || index >= len(instanceSections) {
-		msg := tgbotapi.NewMessage(chatID, "无效的模板选择")
+	template, err := getLaunchTemplate(index)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, err.Error())
 		bot.Send(msg)
 		return
 	}
 
-	instanceSection := instanceSections[index]
-	var newInstance Instance
-	err := instanceSection.MapTo(&newInstance)
+	newInstance, err := mapTemplateToInstance(template)
 	if err != nil {
-		msg := tgbotapi.NewMessage(chatID, "解析实例模板参数失败: "+err.Error())
+		msg := tgbotapi.NewMessage(chatID, err.Error())
 		bot.Send(msg)
 		return
 	}
@@ -1031,6 +1606,7 @@ func confirmCreateInstance(chatID int64, index int) {
 	}
 
 	messageText := fmt.Sprintf("确认创建以下配置的实例：\n\n"+
+		"模板: %s\n"+
 		"形状: %s\n"+
 		"CPU: %g\n"+
 		"内存: %g GB\n"+
@@ -1038,7 +1614,7 @@ func confirmCreateInstance(chatID int64, index int) {
 		"引导卷大小: %d GB\n"+
 		"可用性域: %s\n\n"+
 		"是否确认创建？",
-		instance.Shape, instance.Ocpus, instance.MemoryInGBs,
+		template.DisplayName(), instance.Shape, instance.Ocpus, instance.MemoryInGBs,
 		instance.OperatingSystem, instance.OperatingSystemVersion,
 		instance.BootVolumeSizeInGBs, instance.AvailabilityDomain)
 
@@ -1059,27 +1635,33 @@ func startCreateInstance(chatID int64) {
 	msg := tgbotapi.NewMessage(chatID, "正在创建实例，请稍候...")
 	sentMsg, _ := bot.Send(msg)
 
-	getInstanceCopy()
+	// 抢机可能持续很久，在后台使用当前账号和实例配置的副本执行，
+	// 期间仍可重新加载配置、切换账号和处理其他操作
+	s := currentSession()
+	ins := getInstanceCopy()
+	accountIndex := getCurrentAccountIndex()
+	go func() {
+		sum, num := LaunchInstances(s, ins)
 
-	sum, num := LaunchInstances(availabilityDomains)
+		log.Printf("创建实例完成，总数: %d, 成功: %d", sum, num)
+		resultMsg := fmt.Sprintf("创建实例结果 (账号: %s)：\n总数: %d\n成功: %d\n失败: %d", s.name, sum, num, sum-num)
+		editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, resultMsg)
 
-	log.Printf("创建实例完成，总数: %d, 成功: %d", sum, num)
-	resultMsg := fmt.Sprintf("创建实例结果：\n总数: %d\n成功: %d\n失败: %d", sum, num, sum-num)
-	editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, resultMsg)
-
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("返回实例列表", "account_action:list_instances"),
-			tgbotapi.NewInlineKeyboardButtonData("返回主菜单", "select_account:"+strconv.Itoa(getCurrentAccountIndex())),
-		),
-	)
-	editMsg.ReplyMarkup = &keyboard
-	bot.Send(editMsg)
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("返回实例列表", "account_action:list_instances"),
+				tgbotapi.NewInlineKeyboardButtonData("返回主菜单", "select_account:"+strconv.Itoa(accountIndex)),
+			),
+		)
+		editMsg.ReplyMarkup = &keyboard
+		bot.Send(editMsg)
+	}()
 }
 func sendMainMenu(chatID int64) {
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("选择账户", "list_accounts"),
+			tgbotapi.NewInlineKeyboardButtonData("所有账户概览", "all_accounts_overview"),
 		),
 	)
 
@@ -1092,7 +1674,7 @@ func sendMainMenu(chatID int64) {
 func sendAccountList(chatID int64) {
 	var keyboard [][]tgbotapi.InlineKeyboardButton
 
-	for i, section := range oracleSections {
+	for i, section := range getConfig().oracleSections {
 		button := tgbotapi.NewInlineKeyboardButtonData(section.Name(), fmt.Sprintf("select_account:%d", i))
 		row := tgbotapi.NewInlineKeyboardRow(button)
 		keyboard = append(keyboard, row)
@@ -1109,36 +1691,53 @@ func sendAccountList(chatID int64) {
 }
 
 func selectAccount(chatID int64, accountIndex int) {
-	if accountIndex < 0 || accountIndex >= len(oracleSections) {
+	sections := getConfig().oracleSections
+	if accountIndex < 0 || accountIndex >= len(sections) {
 		msg := tgbotapi.NewMessage(chatID, "无效的账户选择")
 		bot.Send(msg)
 		return
 	}
 
-	oracleSection = oracleSections[accountIndex]
+	oracleSection = sections[accountIndex]
 	err := initVar(oracleSection)
 	if err != nil {
 		msg := tgbotapi.NewMessage(chatID, "初始化账户失败："+err.Error())
 		bot.Send(msg)
 		return
 	}
+	sendAccountMenu(chatID)
+}
 
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+func sendAccountMenu(chatID int64) {
+	keyboard := [][]tgbotapi.InlineKeyboardButton{
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("查看实例", "account_action:list_instances"),
 			tgbotapi.NewInlineKeyboardButtonData("创建实例", "account_action:create_instance"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("管理引导卷", "account_action:manage_boot_volumes"),
+			tgbotapi.NewInlineKeyboardButtonData("管理存储", "account_action:manage_storage"),
 			tgbotapi.NewInlineKeyboardButtonData("查看成本", "account_action:view_cost"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("返回主菜单", "main_menu"),
+			tgbotapi.NewInlineKeyboardButtonData("管理模板", "account_action:manage_templates"),
 		),
-	)
+	}
+	if len(accountRegions) > 1 {
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("切换区域", "account_action:switch_region"),
+			tgbotapi.NewInlineKeyboardButtonData("切换区间", "account_action:switch_compartment"),
+		))
+	} else {
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("切换区间", "account_action:switch_compartment"),
+		))
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("返回主菜单", "main_menu"),
+	))
 
-	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("已选择账户：%s\n请选择操作：", oracleSection.Name()))
-	msg.ReplyMarkup = keyboard
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("已选择账户：%s\n当前区域：%s\n当前区间：%s\n请选择操作：", oracleSection.Name(), oracle.Region, getCompartmentName(compartmentId)))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
 
 	bot.Send(msg)
 }
@@ -1151,8 +1750,22 @@ func handleAccountAction(chatID int64, action string) {
 		createInstanceTelegram(chatID)
 	case "manage_boot_volumes":
 		manageBootVolumesTelegram(chatID)
+	case "boot_volume_backups":
+		manageBootVolumeBackupsTelegram(chatID)
+	case "manage_storage":
+		manageStorageTelegram(chatID)
+	case "manage_block_volumes":
+		manageBlockVolumesTelegram(chatID)
+	case "create_block_volume":
+		promptCreateBlockVolume(chatID)
 	case "view_cost":
 		viewCostTelegram(chatID)
+	case "switch_region":
+		selectRegionTelegram(chatID)
+	case "switch_compartment":
+		selectCompartmentTelegram(chatID)
+	case "manage_templates":
+		manageTemplatesTelegram(chatID)
 	default:
 		msg := tgbotapi.NewMessage(chatID, "未知操作")
 		bot.Send(msg)
@@ -1163,20 +1776,7 @@ func listInstancesTelegram(chatID int64) {
 	msg := tgbotapi.NewMessage(chatID, "正在获取实例数据...")
 	sentMsg, _ := bot.Send(msg)
 
-	var instances []core.Instance
-	var nextPage *string
-	var err error
-	for {
-		var ins []core.Instance
-		ins, nextPage, err = ListInstances(ctx, computeClient, nextPage)
-		if err == nil {
-			instances = append(instances, ins...)
-		}
-		if nextPage == nil || len(ins) == 0 {
-			break
-		}
-	}
-
+	instances, err := ListInstances(ctx, computeClient)
 	if err != nil {
 		editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, "获取实例失败: "+err.Error())
 		bot.Send(editMsg)
@@ -1192,15 +1792,25 @@ func listInstancesTelegram(chatID int64) {
 	var messageText strings.Builder
 	messageText.WriteString("实例列表：\n\n")
 
-	var keyboard [][]tgbotapi.InlineKeyboardButton
+	// 序号和实例详情使用完整列表中的下标，筛选、排序和翻页时保持不变
+	indexes := getFilteredIndexes("instances", instanceListItems(instances), &messageText)
+	if len(indexes) == 0 {
+		messageText.WriteString("没有符合筛选条件的实例\n")
+	}
 
-	for i, ins := range instances {
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, i := range getPageIndexes("instances", indexes) {
+		ins := instances[i]
 		messageText.WriteString(fmt.Sprintf("%d. %s (状态: %s)\n", i+1, *ins.DisplayName, getInstanceState(ins.LifecycleState)))
 		button := tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("实例 %d", i+1), fmt.Sprintf("instance_details:%d", i))
 		row := tgbotapi.NewInlineKeyboardRow(button)
 		keyboard = append(keyboard, row)
 	}
 
+	if row := pageButtons("instances", len(indexes)); row != nil {
+		keyboard = append(keyboard, row)
+	}
+	keyboard = append(keyboard, listFilterButtons("instances"))
 	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
 		tgbotapi.NewInlineKeyboardButtonData("返回", "select_account:"+strconv.Itoa(getCurrentAccountIndex())),
 	))
@@ -1213,7 +1823,7 @@ func listInstancesTelegram(chatID int64) {
 }
 
 func getCurrentAccountIndex() int {
-	for i, section := range oracleSections {
+	for i, section := range getConfig().oracleSections {
 		if section == oracleSection {
 			return i
 		}
@@ -1229,11 +1839,32 @@ func initVar(oracleSec *ini.Section) (err error) {
 		printlnErr("解析账号相关参数失败", err.Error())
 		return
 	}
+	err = initClients()
+	if err != nil {
+		return
+	}
+	accountRegions, err = getAccountRegions(oracle, provider)
+	if err != nil {
+		printlnErr("获取账号区域失败", err.Error())
+		accountRegions = []string{oracle.Region}
+	}
+	err = initCompartment()
+	if err != nil {
+		printlnErr("初始化区间失败", err.Error())
+		compartmentRecursive = false
+		return initCompartment()
+	}
+	return nil
+}
+
+// 根据当前账号和区域创建客户端
+func initClients() (err error) {
 	provider, err = getProvider(oracle)
 	if err != nil {
 		printlnErr("获取 Provider 失败", err.Error())
 		return
 	}
+	completeOracle(&oracle, provider)
 
 	computeClient, err = core.NewComputeClientWithConfigurationProvider(provider)
 	if err != nil {
@@ -1241,24 +1872,31 @@ func initVar(oracleSec *ini.Section) (err error) {
 		return
 	}
 	setProxyOrNot(&computeClient.BaseClient)
+	setCacheInterceptor(&computeClient.BaseClient)
+	setRateLimitInterceptor(&computeClient.BaseClient, oracle.Tenancy)
 	networkClient, err = core.NewVirtualNetworkClientWithConfigurationProvider(provider)
 	if err != nil {
 		printlnErr("创建 VirtualNetworkClient 失败", err.Error())
 		return
 	}
 	setProxyOrNot(&networkClient.BaseClient)
+	setCacheInterceptor(&networkClient.BaseClient)
+	setRateLimitInterceptor(&networkClient.BaseClient, oracle.Tenancy)
 	storageClient, err = core.NewBlockstorageClientWithConfigurationProvider(provider)
 	if err != nil {
 		printlnErr("创建 BlockstorageClient 失败", err.Error())
 		return
 	}
 	setProxyOrNot(&storageClient.BaseClient)
+	setCacheInterceptor(&storageClient.BaseClient)
+	setRateLimitInterceptor(&storageClient.BaseClient, oracle.Tenancy)
 	identityClient, err = identity.NewIdentityClientWithConfigurationProvider(provider)
 	if err != nil {
 		printlnErr("创建 IdentityClient 失败", err.Error())
 		return
 	}
 	setProxyOrNot(&identityClient.BaseClient)
+	setRateLimitInterceptor(&identityClient.BaseClient, oracle.Tenancy)
 	// 获取可用性域
 	availabilityDomains, err = ListAvailabilityDomains()
 	if err != nil {
@@ -1268,13 +1906,14 @@ func initVar(oracleSec *ini.Section) (err error) {
 }
 
 // 返回值 sum: 创建实例总数; num: 创建成功的个数
-func LaunchInstances(ads []identity.AvailabilityDomain) (sum, num int32) {
+func LaunchInstances(s *session, ins Instance) (sum, num int32) {
 	/* 创建实例的几种情况
 	 * 1. 设置了 availabilityDomain 参数，即在设置的可用性域中创建 sum 个实例。
 	 * 2. 没有设置 availabilityDomain 但是设置了 each 参数。即在获取的每个可用性域中创建 each 个实例，创建的实例总数 sum =  each * adCount。
 	 * 3. 没有设置 availabilityDomain 且没有设置 each 参数，即在获取到的可用性域中创建的实例总数为 sum。
 	 */
 	// 检查可用性域列表是否为空
+	ads := s.availabilityDomains
 	if len(ads) == 0 {
 		log.Println("错误：可用性域列表为空")
 		return 0, 0
@@ -1282,14 +1921,36 @@ func LaunchInstances(ads []identity.AvailabilityDomain) (sum, num int32) {
 
 	//可用性域数量
 	var adCount int32 = int32(len(ads))
-	adName := common.String(instance.AvailabilityDomain)
-	each := instance.Each
-	sum = instance.Sum
+	adName := common.String(ins.AvailabilityDomain)
+	each := ins.Each
+	sum = ins.Sum
+
+	// 使用已有引导卷创建实例时，只能在引导卷所在的可用性域中创建，且只能创建一个实例。
+	var bootVolume core.BootVolume
+	var err error
+	if ins.BootVolumeId != "" {
+		fmt.Println("正在获取引导卷...")
+		bootVolume, err = getBootVolume(s.storage, common.String(ins.BootVolumeId))
+		if err != nil {
+			printlnErr("获取引导卷失败", err.Error())
+			return
+		}
+		if bootVolume.LifecycleState != core.BootVolumeLifecycleStateAvailable {
+			printlnErr("引导卷不可用", getBootVolumeState(bootVolume.LifecycleState))
+			return
+		}
+		fmt.Println("引导卷:", *bootVolume.DisplayName)
+		adName = bootVolume.AvailabilityDomain
+		each = 0
+		sum = 1
+	}
 
 	// 没有设置可用性域并且没有设置each时，才有用。
 	var usableAds = make([]identity.AvailabilityDomain, 0)
 
 	//可用性域不固定，即没有提供 availabilityDomain 参数
+	// 是否发送 Telegram 消息
+	EACH := getConfig().each
 	var AD_NOT_FIXED bool = false
 	var EACH_AD = false
 	if adName == nil || *adName == "" {
@@ -1303,7 +1964,7 @@ func LaunchInstances(ads []identity.AvailabilityDomain) (sum, num int32) {
 		}
 	}
 
-	name := instance.InstanceDisplayName
+	name := ins.InstanceDisplayName
 	if name == "" {
 		name = time.Now().Format("instance-20060102-1504")
 	}
@@ -1313,26 +1974,42 @@ func LaunchInstances(ads []identity.AvailabilityDomain) (sum, num int32) {
 	}
 	// create the launch instance request
 	request := core.LaunchInstanceRequest{}
-	request.CompartmentId = common.String(oracle.Tenancy)
+	request.CompartmentId = getLaunchCompartmentId(s, ins)
 	request.DisplayName = displayName
 
-	// Get a image.
-	fmt.Println("正在获取系统镜像...")
-	image, err := GetImage(ctx, computeClient)
+	job, err := startJob(ins.Tags, ins.DefinedTags)
 	if err != nil {
-		printlnErr("获取系统镜像失败", err.Error())
+		printlnErr("解析模板标签失败", err.Error())
 		return
 	}
-	fmt.Println("系统镜像:", *image.DisplayName)
+	fmt.Println("任务ID:", job.Id)
+	request.FreeformTags = job.freeformTags()
+	request.DefinedTags = job.definedTags()
+
+	// Get a image.
+	var image core.Image
+	if ins.BootVolumeId == "" {
+		fmt.Println("正在获取系统镜像...")
+		image, err = GetImage(ctx, s.compute, s.oracle.Tenancy, ins)
+		if err != nil {
+			printlnErr("获取系统镜像失败", err.Error())
+			return
+		}
+		fmt.Println("系统镜像:", *image.DisplayName)
+	} else if bootVolume.ImageId != nil {
+		// 引导卷中记录了创建时使用的镜像，用于获取兼容的 Shape。
+		// 从备份恢复或克隆的引导卷可能没有镜像信息，此时从所有 Shape 中查找
+		image.Id = bootVolume.ImageId
+	}
 
 	var shape core.Shape
-	if strings.Contains(strings.ToLower(instance.Shape), "flex") && instance.Ocpus > 0 && instance.MemoryInGBs > 0 {
-		shape.Shape = &instance.Shape
-		shape.Ocpus = &instance.Ocpus
-		shape.MemoryInGBs = &instance.MemoryInGBs
+	if strings.Contains(strings.ToLower(ins.Shape), "flex") && ins.Ocpus > 0 && ins.MemoryInGBs > 0 {
+		shape.Shape = &ins.Shape
+		shape.Ocpus = &ins.Ocpus
+		shape.MemoryInGBs = &ins.MemoryInGBs
 	} else {
 		fmt.Println("正在获取Shape信息...")
-		shape, err = getShape(image.Id, instance.Shape)
+		shape, err = getShape(s.compute, s.oracle.Tenancy, image.Id, ins.Shape)
 		if err != nil {
 			printlnErr("获取Shape信息失败", err.Error())
 			return
@@ -1345,45 +2022,55 @@ func LaunchInstances(ads []identity.AvailabilityDomain) (sum, num int32) {
 			Ocpus:       shape.Ocpus,
 			MemoryInGBs: shape.MemoryInGBs,
 		}
-		if instance.Burstable == "1/8" {
+		if ins.Burstable == "1/8" {
 			request.ShapeConfig.BaselineOcpuUtilization = core.LaunchInstanceShapeConfigDetailsBaselineOcpuUtilization8
-		} else if instance.Burstable == "1/2" {
+		} else if ins.Burstable == "1/2" {
 			request.ShapeConfig.BaselineOcpuUtilization = core.LaunchInstanceShapeConfigDetailsBaselineOcpuUtilization2
 		}
 	}
 
 	// create a subnet or get the one already created
 	fmt.Println("正在获取子网...")
-	subnet, err := CreateOrGetNetworkInfrastructure(ctx, networkClient)
+	subnet, err := CreateOrGetNetworkInfrastructure(ctx, s.network, common.String(s.compartmentId), ins, job)
 	if err != nil {
 		printlnErr("获取子网失败", err.Error())
 		return
 	}
 	fmt.Println("子网:", *subnet.DisplayName)
-	request.CreateVnicDetails = &core.CreateVnicDetails{SubnetId: subnet.Id}
+	request.CreateVnicDetails = &core.CreateVnicDetails{
+		SubnetId:     subnet.Id,
+		FreeformTags: request.FreeformTags,
+		DefinedTags:  request.DefinedTags,
+	}
 
-	sd := core.InstanceSourceViaImageDetails{}
-	sd.ImageId = image.Id
-	if instance.BootVolumeSizeInGBs > 0 {
-		sd.BootVolumeSizeInGBs = common.Int64(instance.BootVolumeSizeInGBs)
+	if ins.BootVolumeId != "" {
+		request.SourceDetails = core.InstanceSourceViaBootVolumeDetails{BootVolumeId: bootVolume.Id}
+	} else {
+		sd := core.InstanceSourceViaImageDetails{}
+		sd.ImageId = image.Id
+		if ins.BootVolumeSizeInGBs > 0 {
+			sd.BootVolumeSizeInGBs = common.Int64(ins.BootVolumeSizeInGBs)
+		}
+		request.SourceDetails = sd
 	}
-	request.SourceDetails = sd
 	request.IsPvEncryptionInTransitEnabled = common.Bool(true)
 
 	metaData := map[string]string{}
-	metaData["ssh_authorized_keys"] = instance.SSH_Public_Key
-	if instance.CloudInit != "" {
-		metaData["user_data"] = instance.CloudInit
+	metaData["ssh_authorized_keys"] = ins.SSH_Public_Key
+	if ins.CloudInit != "" {
+		metaData["user_data"] = ins.CloudInit
 	}
 	request.Metadata = metaData
 
-	minTime := instance.MinTime
-	maxTime := instance.MaxTime
+	// 抢机循环自行处理重试间隔和限流，创建请求不再由 SDK 重试
+	noRetryPolicy := common.NoRetryPolicy()
+	request.RequestMetadata = common.RequestMetadata{RetryPolicy: &noRetryPolicy}
+	backoff := &launchBackoff{tenancy: s.oracle.Tenancy, minTime: ins.MinTime, maxTime: ins.MaxTime}
 
 	SKIP_RETRY_MAP := make(map[int32]bool)
 	var usableAdsTemp = make([]identity.AvailabilityDomain, 0)
 
-	retry := instance.Retry // 重试次数
+	retry := ins.Retry      // 重试次数
 	var failTimes int32 = 0 // 失败次数
 
 	// 记录尝试创建实例的次数
@@ -1396,22 +2083,46 @@ func LaunchInstances(ads []identity.AvailabilityDomain) (sum, num int32) {
 	var startTime = time.Now()
 
 	var bootVolumeSize float64
-	if instance.BootVolumeSizeInGBs > 0 {
-		bootVolumeSize = float64(instance.BootVolumeSizeInGBs)
+	if ins.BootVolumeId != "" {
+		bootVolumeSize = float64(*bootVolume.SizeInGBs)
+	} else if ins.BootVolumeSizeInGBs > 0 {
+		bootVolumeSize = float64(ins.BootVolumeSizeInGBs)
 	} else {
 		bootVolumeSize = math.Round(float64(*image.SizeInMBs) / float64(1024))
 	}
-	printf("\033[1;36m[%s] 开始创建 %s 实例, OCPU: %g 内存: %g 引导卷: %g \033[0m\n", oracleSectionName, *shape.Shape, *shape.Ocpus, *shape.MemoryInGBs, bootVolumeSize)
+	printf("\033[1;36m[%s] 开始创建 %s 实例, OCPU: %g 内存: %g 引导卷: %g \033[0m\n", s.name, *shape.Shape, *shape.Ocpus, *shape.MemoryInGBs, bootVolumeSize)
+	// 正在创建的状态消息，重试时更新尝试次数和当前等待时间
+	creatingText := func() string {
+		return fmt.Sprintf("正在尝试创建第 %d 个实例...⏳\n区域: %s\n实例配置: %s\nOCPU计数: %g\n内存(GB): %g\n引导卷(GB): %g\n创建个数: %d", pos+1, s.oracle.Region, *shape.Shape, *shape.Ocpus, *shape.MemoryInGBs, bootVolumeSize, sum)
+	}
+	var statusMsg Message
+	var statusErr error
+	var statusReason string
+	var statusTime time.Time
 	if EACH {
-		text := fmt.Sprintf("正在尝试创建第 %d 个实例...⏳\n区域: %s\n实例配置: %s\nOCPU计数: %g\n内存(GB): %g\n引导卷(GB): %g\n创建个数: %d", pos+1, oracle.Region, *shape.Shape, *shape.Ocpus, *shape.MemoryInGBs, bootVolumeSize, sum)
-		_, err := sendMessage("", text)
-		if err != nil {
-			printlnErr("Telegram 消息提醒发送失败", err.Error())
+		statusMsg, statusErr = sendMessage("", creatingText())
+		if statusErr != nil {
+			printlnErr("Telegram 消息提醒发送失败", statusErr.Error())
 		}
 	}
 
+	// 每个实例使用单独生成的 SSH 密钥，创建成功后保存私钥并生成下一个
+	var generatedKey *generatedSSHKey
 	for pos < sum {
 
+		if ins.GenerateSSHKey && generatedKey == nil {
+			generatedKey, err = generateSSHKey(*request.DisplayName)
+			if err != nil {
+				printlnErr("生成 SSH 密钥失败", err.Error())
+				return sum, num
+			}
+			keys := generatedKey.PublicKey
+			if ins.SSH_Public_Key != "" {
+				keys = ins.SSH_Public_Key + "\n" + keys
+			}
+			metaData["ssh_authorized_keys"] = keys
+		}
+
 		if AD_NOT_FIXED {
 			if EACH_AD {
 				if pos%each == 0 && failTimes == 0 {
@@ -1437,10 +2148,10 @@ func LaunchInstances(ads []identity.AvailabilityDomain) (sum, num int32) {
 		}
 
 		runTimes++
-		printf("\033[1;36m[%s] 正在尝试创建第 %d 个实例, AD: %s\033[0m\n", oracleSectionName, pos+1, *adName)
-		printf("\033[1;36m[%s] 当前尝试次数: %d \033[0m\n", oracleSectionName, runTimes)
+		printf("\033[1;36m[%s] 正在尝试创建第 %d 个实例, AD: %s\033[0m\n", s.name, pos+1, *adName)
+		printf("\033[1;36m[%s] 当前尝试次数: %d \033[0m\n", s.name, runTimes)
 		request.AvailabilityDomain = adName
-		createResp, err := computeClient.LaunchInstance(ctx, request)
+		createResp, err := s.compute.LaunchInstance(ctx, request)
 
 		if err == nil {
 			// 创建实例成功
@@ -1449,24 +2160,32 @@ func LaunchInstances(ads []identity.AvailabilityDomain) (sum, num int32) {
 
 			duration := fmtDuration(time.Since(startTime))
 
-			printf("\033[1;32m[%s] 第 %d 个实例抢到了🎉, 正在启动中请稍等...⌛️ \033[0m\n", oracleSectionName, pos+1)
+			printf("\033[1;32m[%s] 第 %d 个实例抢到了🎉, 正在启动中请稍等...⌛️ \033[0m\n", s.name, pos+1)
+			if generatedKey != nil {
+				if path, err := saveGeneratedSSHKey(generatedKey, *createResp.Instance.DisplayName); err != nil {
+					printlnErr("保存 SSH 私钥失败", err.Error())
+				} else {
+					printf("\033[1;32m[%s] SSH 私钥已保存到 %s\033[0m\n", s.name, path)
+				}
+				generatedKey = nil
+			}
 			var msg Message
 			var msgErr error
 			var text string
 			if EACH {
-				text = fmt.Sprintf("第 %d 个实例抢到了🎉, 正在启动中请稍等...⌛️\n区域: %s\n实例名称: %s\n公共IP: 获取中...⏳\n可用性域:%s\n实例配置: %s\nOCPU计数: %g\n内存(GB): %g\n引导卷(GB): %g\n创建个数: %d\n尝试次数: %d\n耗时: %s", pos+1, oracle.Region, *createResp.Instance.DisplayName, *createResp.Instance.AvailabilityDomain, *shape.Shape, *shape.Ocpus, *shape.MemoryInGBs, bootVolumeSize, sum, runTimes, duration)
+				text = fmt.Sprintf("第 %d 个实例抢到了🎉, 正在启动中请稍等...⌛️\n区域: %s\n实例名称: %s\n公共IP: 获取中...⏳\n可用性域:%s\n实例配置: %s\nOCPU计数: %g\n内存(GB): %g\n引导卷(GB): %g\n创建个数: %d\n尝试次数: %d\n耗时: %s", pos+1, s.oracle.Region, *createResp.Instance.DisplayName, *createResp.Instance.AvailabilityDomain, *shape.Shape, *shape.Ocpus, *shape.MemoryInGBs, bootVolumeSize, sum, runTimes, duration)
 				msg, msgErr = sendMessage("", text)
 			}
 			// 获取实例公共IP
 			var strIps string
-			ips, err := getInstancePublicIps(createResp.Instance.Id)
+			ips, err := getInstancePublicIps(s, createResp.Instance.Id)
 			if err != nil {
-				printf("\033[1;32m[%s] 第 %d 个实例抢到了🎉, 但是启动失败❌ 错误信息: \033[0m%s\n", oracleSectionName, pos+1, err.Error())
-				text = fmt.Sprintf("第 %d 个实例抢到了🎉, 但是启动失败❌实例已被终止😔\n区域: %s\n实例名称: %s\n可用性域:%s\n实例配置: %s\nOCPU计数: %g\n内存(GB): %g\n引导卷(GB): %g\n创建个数: %d\n尝试次数: %d\n耗时: %s", pos+1, oracle.Region, *createResp.Instance.DisplayName, *createResp.Instance.AvailabilityDomain, *shape.Shape, *shape.Ocpus, *shape.MemoryInGBs, bootVolumeSize, sum, runTimes, duration)
+				printf("\033[1;32m[%s] 第 %d 个实例抢到了🎉, 但是启动失败❌ 错误信息: \033[0m%s\n", s.name, pos+1, err.Error())
+				text = fmt.Sprintf("第 %d 个实例抢到了🎉, 但是启动失败❌实例已被终止😔\n区域: %s\n实例名称: %s\n可用性域:%s\n实例配置: %s\nOCPU计数: %g\n内存(GB): %g\n引导卷(GB): %g\n创建个数: %d\n尝试次数: %d\n耗时: %s", pos+1, s.oracle.Region, *createResp.Instance.DisplayName, *createResp.Instance.AvailabilityDomain, *shape.Shape, *shape.Ocpus, *shape.MemoryInGBs, bootVolumeSize, sum, runTimes, duration)
 			} else {
 				strIps = strings.Join(ips, ",")
-				printf("\033[1;32m[%s] 第 %d 个实例抢到了🎉, 启动成功✅. 实例名称: %s, 公共IP: %s\033[0m\n", oracleSectionName, pos+1, *createResp.Instance.DisplayName, strIps)
-				text = fmt.Sprintf("第 %d 个实例抢到了🎉, 启动成功✅\n区域: %s\n实例名称: %s\n公共IP: %s\n可用性域:%s\n实例配置: %s\nOCPU计数: %g\n内存(GB): %g\n引导卷(GB): %g\n创建个数: %d\n尝试次数: %d\n耗时: %s", pos+1, oracle.Region, *createResp.Instance.DisplayName, strIps, *createResp.Instance.AvailabilityDomain, *shape.Shape, *shape.Ocpus, *shape.MemoryInGBs, bootVolumeSize, sum, runTimes, duration)
+				printf("\033[1;32m[%s] 第 %d 个实例抢到了🎉, 启动成功✅. 实例名称: %s, 公共IP: %s\033[0m\n", s.name, pos+1, *createResp.Instance.DisplayName, strIps)
+				text = fmt.Sprintf("第 %d 个实例抢到了🎉, 启动成功✅\n区域: %s\n实例名称: %s\n公共IP: %s\n可用性域:%s\n实例配置: %s\nOCPU计数: %g\n内存(GB): %g\n引导卷(GB): %g\n创建个数: %d\n尝试次数: %d\n耗时: %s", pos+1, s.oracle.Region, *createResp.Instance.DisplayName, strIps, *createResp.Instance.AvailabilityDomain, *shape.Shape, *shape.Ocpus, *shape.MemoryInGBs, bootVolumeSize, sum, runTimes, duration)
 			}
 			if EACH {
 				if msgErr != nil {
@@ -1476,7 +2195,8 @@ func LaunchInstances(ads []identity.AvailabilityDomain) (sum, num int32) {
 				}
 			}
 
-			sleepRandomSecond(minTime, maxTime)
+			backoff.next(nil)
+			backoff.wait()
 
 			displayName = common.String(fmt.Sprintf("%s-%d", name, pos+1))
 			request.DisplayName = displayName
@@ -1504,9 +2224,9 @@ func LaunchInstances(ads []identity.AvailabilityDomain) (sum, num int32) {
 					errInfo = servErr.GetMessage()
 				}
 				duration := fmtDuration(time.Since(startTime))
-				printf("\033[1;31m[%s] 第 %d 个实例创建失败了❌, 错误信息: \033[0m%s\n", oracleSectionName, pos+1, errInfo)
+				printf("\033[1;31m[%s] 第 %d 个实例创建失败了❌, 错误信息: \033[0m%s\n", s.name, pos+1, errInfo)
 				if EACH {
-					text := fmt.Sprintf("第 %d 个实例创建失败了❌\n错误信息: %s\n区域: %s\n可用性域: %s\n实例配置: %s\nOCPU计数: %g\n内存(GB): %g\n引导卷(GB): %g\n创建个数: %d\n尝试次数: %d\n耗时:%s", pos+1, errInfo, oracle.Region, *adName, *shape.Shape, *shape.Ocpus, *shape.MemoryInGBs, bootVolumeSize, sum, runTimes, duration)
+					text := fmt.Sprintf("第 %d 个实例创建失败了❌\n错误信息: %s\n区域: %s\n可用性域: %s\n实例配置: %s\nOCPU计数: %g\n内存(GB): %g\n引导卷(GB): %g\n创建个数: %d\n尝试次数: %d\n耗时:%s", pos+1, errInfo, s.oracle.Region, *adName, *shape.Shape, *shape.Ocpus, *shape.MemoryInGBs, bootVolumeSize, sum, runTimes, duration)
 					sendMessage("", text)
 				}
 
@@ -1520,7 +2240,7 @@ func LaunchInstances(ads []identity.AvailabilityDomain) (sum, num int32) {
 				if isServErr {
 					errInfo = servErr.GetMessage()
 				}
-				printf("\033[1;31m[%s] 创建失败, Error: \033[0m%s\n", oracleSectionName, errInfo)
+				printf("\033[1;31m[%s] 创建失败, Error: \033[0m%s\n", s.name, errInfo)
 
 				SKIP_RETRY = false
 				if AD_NOT_FIXED && !EACH_AD {
@@ -1528,7 +2248,15 @@ func LaunchInstances(ads []identity.AvailabilityDomain) (sum, num int32) {
 				}
 			}
 
-			sleepRandomSecond(minTime, maxTime)
+			backoff.next(err)
+			// 等待原因变化或距上次更新超过 statusUpdateInterval 时更新状态消息，避免频繁编辑消息
+			if EACH && !SKIP_RETRY && statusErr == nil &&
+				(backoff.reason != statusReason || time.Since(statusTime) >= statusUpdateInterval) {
+				statusReason, statusTime = backoff.reason, time.Now()
+				text := fmt.Sprintf("%s\n尝试次数: %d\n最近错误: %s\n当前间隔: %s", creatingText(), runTimes, errInfo, backoff)
+				editMessage(statusMsg.MessageId, "", text)
+			}
+			backoff.wait()
 
 			if AD_NOT_FIXED {
 				if !EACH_AD {
@@ -1600,38 +2328,47 @@ func LaunchInstances(ads []identity.AvailabilityDomain) (sum, num int32) {
 		pos++
 
 		if pos < sum && EACH {
-			text := fmt.Sprintf("正在尝试创建第 %d 个实例...⏳\n区域: %s\n实例配置: %s\nOCPU计数: %g\n内存(GB): %g\n引导卷(GB): %g\n创建个数: %d", pos+1, oracle.Region, *shape.Shape, *shape.Ocpus, *shape.MemoryInGBs, bootVolumeSize, sum)
-			sendMessage("", text)
+			statusMsg, statusErr = sendMessage("", creatingText())
+			statusReason = ""
 		}
 	}
 	return
 }
 
-func sleepRandomSecond(min, max int32) {
-	var second int32
-	if min <= 0 || max <= 0 {
-		second = 1
-	} else if min >= max {
-		second = max
-	} else {
-		second = rand.Int31n(max-min) + min
-	}
-	printf("Sleep %d Second...\n", second)
-	time.Sleep(time.Duration(second) * time.Second)
-}
-
 // ExampleLaunchInstance does create an instance
 // NOTE: launch instance will create a new instance and VCN. please make sure delete the instance
 // after execute this sample code, otherwise, you will be charged for the running instance
 
 func getProvider(oracle Oracle) (common.ConfigurationProvider, error) {
-	content, err := ioutil.ReadFile(oracle.Key_file)
+	var p common.ConfigurationProvider
+	var err error
+	switch {
+	case oracle.Auth == authInstancePrincipal:
+		if oracle.Region != "" {
+			return auth.InstancePrincipalConfigurationProviderForRegion(common.StringToRegion(oracle.Region))
+		}
+		return auth.InstancePrincipalConfigurationProvider()
+	case oracle.Auth == authSessionToken || oracle.Profile != "" || oracle.Config_file != "":
+		p, err = getFileProvider(oracle)
+	case oracle.Auth == "" || oracle.Auth == authApiKey:
+		var privateKey string
+		privateKey, err = getPrivateKey(oracle)
+		if err != nil {
+			return nil, err
+		}
+		privateKeyPassphrase := common.String(oracle.Key_password)
+		return common.NewRawConfigurationProvider(oracle.Tenancy, oracle.User, oracle.Region, oracle.Fingerprint, privateKey, privateKeyPassphrase), nil
+	default:
+		return nil, fmt.Errorf("不支持的认证方式: %s", oracle.Auth)
+	}
 	if err != nil {
 		return nil, err
 	}
-	privateKey := string(content)
-	privateKeyPassphrase := common.String(oracle.Key_password)
-	return common.NewRawConfigurationProvider(oracle.Tenancy, oracle.User, oracle.Region, oracle.Fingerprint, privateKey, privateKeyPassphrase), nil
+	// 配置文件中的区域可以被 region 覆盖
+	if region, _ := p.Region(); oracle.Region != "" && oracle.Region != region {
+		p = regionProvider{ConfigurationProvider: p, region: oracle.Region}
+	}
+	return p, nil
 }
 
 func currMouthFirstLastDay() (time.Time, time.Time) {
@@ -1647,42 +2384,42 @@ func currMouthFirstLastDay() (time.Time, time.Time) {
 }
 
 // 创建或获取基础网络设施
-func CreateOrGetNetworkInfrastructure(ctx context.Context, c core.VirtualNetworkClient) (subnet core.Subnet, err error) {
+func CreateOrGetNetworkInfrastructure(ctx context.Context, c core.VirtualNetworkClient, compartmentId *string, ins Instance, job *resourceJob) (subnet core.Subnet, err error) {
 	var vcn core.Vcn
-	vcn, err = createOrGetVcn(ctx, c)
+	vcn, err = createOrGetVcn(ctx, c, compartmentId, ins.VcnDisplayName, job)
 	if err != nil {
 		return
 	}
 	var gateway core.InternetGateway
-	gateway, err = createOrGetInternetGateway(c, vcn.Id)
+	gateway, err = createOrGetInternetGateway(c, compartmentId, vcn.Id, job)
 	if err != nil {
 		return
 	}
-	_, err = createOrGetRouteTable(c, gateway.Id, vcn.Id)
+	_, err = createOrGetRouteTable(c, compartmentId, gateway.Id, vcn.Id)
 	if err != nil {
 		return
 	}
 	subnet, err = createOrGetSubnetWithDetails(
-		ctx, c, vcn.Id,
-		common.String(instance.SubnetDisplayName),
+		ctx, c, compartmentId, vcn.Id,
+		common.String(ins.SubnetDisplayName),
 		common.String("10.0.0.0/20"),
 		common.String("subnetdns"),
-		common.String(instance.AvailabilityDomain))
+		common.String(ins.AvailabilityDomain), job)
 	return
 }
 
 // CreateOrGetSubnetWithDetails either creates a new Virtual Cloud Network (VCN) or get the one already exist
 // with detail info
-func createOrGetSubnetWithDetails(ctx context.Context, c core.VirtualNetworkClient, vcnID *string,
-	displayName *string, cidrBlock *string, dnsLabel *string, availableDomain *string) (subnet core.Subnet, err error) {
+func createOrGetSubnetWithDetails(ctx context.Context, c core.VirtualNetworkClient, compartmentId, vcnID *string,
+	displayName *string, cidrBlock *string, dnsLabel *string, availableDomain *string, job *resourceJob) (subnet core.Subnet, err error) {
 	var subnets []core.Subnet
-	subnets, err = listSubnets(ctx, c, vcnID)
+	subnets, err = listSubnets(ctx, c, compartmentId, vcnID)
 	if err != nil {
 		return
 	}
 
 	if displayName == nil {
-		displayName = common.String(instance.SubnetDisplayName)
+		displayName = common.String("")
 	}
 
 	if len(subnets) > 0 && *displayName == "" {
@@ -1707,10 +2444,12 @@ func createOrGetSubnetWithDetails(ctx context.Context, c core.VirtualNetworkClie
 	}
 	request := core.CreateSubnetRequest{}
 	//request.AvailabilityDomain = availableDomain //省略此属性创建区域性子网(regional subnet)，提供此属性创建特定于可用性域的子网。建议创建区域性子网。
-	request.CompartmentId = &oracle.Tenancy
+	request.CompartmentId = compartmentId
 	request.CidrBlock = cidrBlock
 	request.DisplayName = displayName
 	request.DnsLabel = dnsLabel
+	request.FreeformTags = job.freeformTags()
+	request.DefinedTags = job.definedTags()
 	request.RequestMetadata = getCustomRequestMetadataWithRetryPolicy()
 
 	request.VcnId = vcnID
@@ -1782,35 +2521,35 @@ func createOrGetSubnetWithDetails(ctx context.Context, c core.VirtualNetworkClie
 }
 
 // 列出指定虚拟云网络 (VCN) 中的所有子网
-func listSubnets(ctx context.Context, c core.VirtualNetworkClient, vcnID *string) (subnets []core.Subnet, err error) {
+func listSubnets(ctx context.Context, c core.VirtualNetworkClient, compartmentId, vcnID *string) (subnets []core.Subnet, err error) {
 	request := core.ListSubnetsRequest{
-		CompartmentId:   &oracle.Tenancy,
+		CompartmentId:   compartmentId,
 		VcnId:           vcnID,
 		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
 	}
-	var r core.ListSubnetsResponse
-	r, err = c.ListSubnets(ctx, request)
-	if err != nil {
-		return
-	}
-	subnets = r.Items
+	err = listAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		r, err := c.ListSubnets(ctx, request)
+		subnets = append(subnets, r.Items...)
+		return r.OpcNextPage, err
+	})
 	return
 }
 
 // 创建一个新的虚拟云网络 (VCN) 或获取已经存在的虚拟云网络
-func createOrGetVcn(ctx context.Context, c core.VirtualNetworkClient) (core.Vcn, error) {
+func createOrGetVcn(ctx context.Context, c core.VirtualNetworkClient, compartmentId *string, name string, job *resourceJob) (core.Vcn, error) {
 	var vcn core.Vcn
-	vcnItems, err := listVcns(ctx, c)
+	vcnItems, err := listVcns(ctx, c, compartmentId)
 	if err != nil {
 		return vcn, err
 	}
-	displayName := common.String(instance.VcnDisplayName)
+	displayName := common.String(name)
 	if len(vcnItems) > 0 && *displayName == "" {
 		vcn = vcnItems[0]
 		return vcn, err
 	}
 	for _, element := range vcnItems {
-		if *element.DisplayName == instance.VcnDisplayName {
+		if *element.DisplayName == name {
 			// VCN already created, return it
 			vcn = element
 			return vcn, err
@@ -1824,9 +2563,11 @@ func createOrGetVcn(ctx context.Context, c core.VirtualNetworkClient) (core.Vcn,
 	request := core.CreateVcnRequest{}
 	request.RequestMetadata = getCustomRequestMetadataWithRetryPolicy()
 	request.CidrBlock = common.String("10.0.0.0/16")
-	request.CompartmentId = common.String(oracle.Tenancy)
+	request.CompartmentId = compartmentId
 	request.DisplayName = displayName
 	request.DnsLabel = common.String("vcndns")
+	request.FreeformTags = job.freeformTags()
+	request.DefinedTags = job.definedTags()
 	r, err := c.CreateVcn(ctx, request)
 	if err != nil {
 		return vcn, err
@@ -1837,45 +2578,56 @@ func createOrGetVcn(ctx context.Context, c core.VirtualNetworkClient) (core.Vcn,
 }
 
 // 列出所有虚拟云网络 (VCN)
-func listVcns(ctx context.Context, c core.VirtualNetworkClient) ([]core.Vcn, error) {
+func listVcns(ctx context.Context, c core.VirtualNetworkClient, compartmentId *string) ([]core.Vcn, error) {
 	request := core.ListVcnsRequest{
-		CompartmentId:   &oracle.Tenancy,
+		CompartmentId:   compartmentId,
 		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
 	}
-	r, err := c.ListVcns(ctx, request)
-	if err != nil {
-		return nil, err
-	}
-	return r.Items, err
+	var vcns []core.Vcn
+	err := listAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		r, err := c.ListVcns(ctx, request)
+		vcns = append(vcns, r.Items...)
+		return r.OpcNextPage, err
+	})
+	return vcns, err
 }
 
 // 创建或者获取 Internet 网关
-func createOrGetInternetGateway(c core.VirtualNetworkClient, vcnID *string) (core.InternetGateway, error) {
+func createOrGetInternetGateway(c core.VirtualNetworkClient, compartmentId, vcnID *string, job *resourceJob) (core.InternetGateway, error) {
 	//List Gateways
 	var gateway core.InternetGateway
 	listGWRequest := core.ListInternetGatewaysRequest{
-		CompartmentId:   &oracle.Tenancy,
+		CompartmentId:   compartmentId,
 		VcnId:           vcnID,
 		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
 	}
 
-	listGWRespone, err := c.ListInternetGateways(ctx, listGWRequest)
+	var gateways []core.InternetGateway
+	err := listAllPages(func(page *string) (*string, error) {
+		listGWRequest.Page = page
+		listGWRespone, err := c.ListInternetGateways(ctx, listGWRequest)
+		gateways = append(gateways, listGWRespone.Items...)
+		return listGWRespone.OpcNextPage, err
+	})
 	if err != nil {
 		fmt.Printf("Internet gateway list error: %s\n", err.Error())
 		return gateway, err
 	}
 
-	if len(listGWRespone.Items) >= 1 {
+	if len(gateways) >= 1 {
 		//Gateway with name already exists
-		gateway = listGWRespone.Items[0]
+		gateway = gateways[0]
 	} else {
 		//Create new Gateway
 		fmt.Printf("开始创建Internet网关\n")
 		enabled := true
 		createGWDetails := core.CreateInternetGatewayDetails{
-			CompartmentId: &oracle.Tenancy,
+			CompartmentId: compartmentId,
 			IsEnabled:     &enabled,
 			VcnId:         vcnID,
+			FreeformTags:  job.freeformTags(),
+			DefinedTags:   job.definedTags(),
 		}
 
 		createGWRequest := core.CreateInternetGatewayRequest{
@@ -1895,15 +2647,20 @@ func createOrGetInternetGateway(c core.VirtualNetworkClient, vcnID *string) (cor
 }
 
 // 创建或者获取路由表
-func createOrGetRouteTable(c core.VirtualNetworkClient, gatewayID, VcnID *string) (routeTable core.RouteTable, err error) {
+func createOrGetRouteTable(c core.VirtualNetworkClient, compartmentId, gatewayID, VcnID *string) (routeTable core.RouteTable, err error) {
 	//List Route Table
 	listRTRequest := core.ListRouteTablesRequest{
-		CompartmentId:   &oracle.Tenancy,
+		CompartmentId:   compartmentId,
 		VcnId:           VcnID,
 		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
 	}
-	var listRTResponse core.ListRouteTablesResponse
-	listRTResponse, err = c.ListRouteTables(ctx, listRTRequest)
+	var routeTables []core.RouteTable
+	err = listAllPages(func(page *string) (*string, error) {
+		listRTRequest.Page = page
+		listRTResponse, err := c.ListRouteTables(ctx, listRTRequest)
+		routeTables = append(routeTables, listRTResponse.Items...)
+		return listRTResponse.OpcNextPage, err
+	})
 	if err != nil {
 		fmt.Printf("Route table list error: %s\n", err.Error())
 		return
@@ -1916,10 +2673,10 @@ func createOrGetRouteTable(c core.VirtualNetworkClient, gatewayID, VcnID *string
 		DestinationType: core.RouteRuleDestinationTypeCidrBlock,
 	}
 
-	if len(listRTResponse.Items) >= 1 {
+	if len(routeTables) >= 1 {
 		//Default Route Table found and has at least 1 route rule
-		if len(listRTResponse.Items[0].RouteRules) >= 1 {
-			routeTable = listRTResponse.Items[0]
+		if len(routeTables[0].RouteRules) >= 1 {
+			routeTable = routeTables[0]
 			//Default Route table needs route rule adding
 		} else {
 			fmt.Printf("路由表未添加规则，开始添加Internet路由规则\n")
@@ -1928,7 +2685,7 @@ func createOrGetRouteTable(c core.VirtualNetworkClient, gatewayID, VcnID *string
 			}
 
 			updateRTRequest := core.UpdateRouteTableRequest{
-				RtId:                    listRTResponse.Items[0].Id,
+				RtId:                    routeTables[0].Id,
 				UpdateRouteTableDetails: updateRTDetails,
 				RequestMetadata:         getCustomRequestMetadataWithRetryPolicy(),
 			}
@@ -1944,45 +2701,51 @@ func createOrGetRouteTable(c core.VirtualNetworkClient, gatewayID, VcnID *string
 
 	} else {
 		//No default route table found
-		fmt.Printf("Error could not find VCN default route table, VCN OCID: %s Could not find route table.\n", *VcnID)
+		err = fmt.Errorf("未找到 VCN 的默认路由表, VCN OCID: %s", *VcnID)
 	}
 	return
 }
 
 // 获取符合条件系统镜像中的第一个
-func GetImage(ctx context.Context, c core.ComputeClient) (image core.Image, err error) {
+func GetImage(ctx context.Context, c core.ComputeClient, tenancy string, ins Instance) (image core.Image, err error) {
 	var images []core.Image
-	images, err = listImages(ctx, c)
+	images, err = listImages(ctx, c, tenancy, ins)
 	if err != nil {
 		return
 	}
 	if len(images) > 0 {
 		image = images[0]
 	} else {
-		err = fmt.Errorf("未找到[%s %s]的镜像, 或该镜像不支持[%s]", instance.OperatingSystem, instance.OperatingSystemVersion, instance.Shape)
+		err = fmt.Errorf("未找到[%s %s]的镜像, 或该镜像不支持[%s]", ins.OperatingSystem, ins.OperatingSystemVersion, ins.Shape)
 	}
 	return
 }
 
 // 列出所有符合条件的系统镜像
-func listImages(ctx context.Context, c core.ComputeClient) ([]core.Image, error) {
-	if instance.OperatingSystem == "" || instance.OperatingSystemVersion == "" {
+func listImages(ctx context.Context, c core.ComputeClient, tenancy string, ins Instance) ([]core.Image, error) {
+	if ins.OperatingSystem == "" || ins.OperatingSystemVersion == "" {
 		return nil, errors.New("操作系统类型和版本不能为空, 请检查配置文件")
 	}
 	request := core.ListImagesRequest{
-		CompartmentId:          common.String(oracle.Tenancy),
-		OperatingSystem:        common.String(instance.OperatingSystem),
-		OperatingSystemVersion: common.String(instance.OperatingSystemVersion),
-		Shape:                  common.String(instance.Shape),
+		CompartmentId:          common.String(tenancy),
+		OperatingSystem:        common.String(ins.OperatingSystem),
+		OperatingSystemVersion: common.String(ins.OperatingSystemVersion),
+		Shape:                  common.String(ins.Shape),
 		RequestMetadata:        getCustomRequestMetadataWithRetryPolicy(),
 	}
-	r, err := c.ListImages(ctx, request)
-	return r.Items, err
+	var images []core.Image
+	err := listAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		r, err := c.ListImages(ctx, request)
+		images = append(images, r.Items...)
+		return r.OpcNextPage, err
+	})
+	return images, err
 }
 
-func getShape(imageId *string, shapeName string) (core.Shape, error) {
+func getShape(c core.ComputeClient, tenancy string, imageId *string, shapeName string) (core.Shape, error) {
 	var shape core.Shape
-	shapes, err := listShapes(ctx, computeClient, imageId)
+	shapes, err := listShapes(ctx, c, tenancy, imageId)
 	if err != nil {
 		return shape, err
 	}
@@ -1997,17 +2760,23 @@ func getShape(imageId *string, shapeName string) (core.Shape, error) {
 }
 
 // ListShapes Lists the shapes that can be used to launch an instance within the specified compartment.
-func listShapes(ctx context.Context, c core.ComputeClient, imageID *string) ([]core.Shape, error) {
+func listShapes(ctx context.Context, c core.ComputeClient, tenancy string, imageID *string) ([]core.Shape, error) {
 	request := core.ListShapesRequest{
-		CompartmentId:   common.String(oracle.Tenancy),
+		CompartmentId:   common.String(tenancy),
 		ImageId:         imageID,
 		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
 	}
-	r, err := c.ListShapes(ctx, request)
-	if err == nil && (r.Items == nil || len(r.Items) == 0) {
+	var shapes []core.Shape
+	err := listAllPages(func(page *string) (*string, error) {
+		request.Page = page
+		r, err := c.ListShapes(ctx, request)
+		shapes = append(shapes, r.Items...)
+		return r.OpcNextPage, err
+	})
+	if err == nil && len(shapes) == 0 {
 		err = errors.New("没有符合条件的Shape")
 	}
-	return r.Items, err
+	return shapes, err
 }
 
 // 列出符合条件的可用性域
@@ -2020,29 +2789,53 @@ func ListAvailabilityDomains() ([]identity.AvailabilityDomain, error) {
 	return resp.Items, err
 }
 
-func ListInstances(ctx context.Context, c core.ComputeClient, page *string) ([]core.Instance, *string, error) {
-	req := core.ListInstancesRequest{
-		CompartmentId:   common.String(oracle.Tenancy),
-		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
-		Limit:           common.Int(100),
-		Page:            page,
-	}
-	resp, err := c.ListInstances(ctx, req)
-	return resp.Items, resp.OpcNextPage, err
+// 列出当前区间(包含子区间时为所有子区间)中的所有实例
+func ListInstances(ctx context.Context, c core.ComputeClient) ([]core.Instance, error) {
+	value, err := getCached(cacheKey("instances"), func() (interface{}, error) {
+		var instances []core.Instance
+		for _, id := range listCompartmentIds {
+			req := core.ListInstancesRequest{
+				CompartmentId:   common.String(id),
+				RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+				Limit:           common.Int(100),
+			}
+			err := listAllPages(func(page *string) (*string, error) {
+				req.Page = page
+				resp, err := c.ListInstances(ctx, req)
+				instances = append(instances, resp.Items...)
+				return resp.OpcNextPage, err
+			})
+			if err != nil {
+				return instances, err
+			}
+		}
+		return instances, nil
+	})
+	instances, _ := value.([]core.Instance)
+	return instances, err
 }
 
-func ListVnicAttachments(ctx context.Context, c core.ComputeClient, instanceId *string, page *string) ([]core.VnicAttachment, *string, error) {
+func ListVnicAttachments(ctx context.Context, c core.ComputeClient, compartmentId, instanceId *string) ([]core.VnicAttachment, error) {
 	req := core.ListVnicAttachmentsRequest{
-		CompartmentId:   common.String(oracle.Tenancy),
+		CompartmentId:   compartmentId,
 		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
 		Limit:           common.Int(100),
-		Page:            page,
 	}
 	if instanceId != nil && *instanceId != "" {
 		req.InstanceId = instanceId
+		// VNIC 附件与实例在同一区间
+		if ins, err := getInstance(c, instanceId); err == nil {
+			req.CompartmentId = ins.CompartmentId
+		}
 	}
-	resp, err := c.ListVnicAttachments(ctx, req)
-	return resp.Items, resp.OpcNextPage, err
+	var attachments []core.VnicAttachment
+	err := listAllPages(func(page *string) (*string, error) {
+		req.Page = page
+		resp, err := c.ListVnicAttachments(ctx, req)
+		attachments = append(attachments, resp.Items...)
+		return resp.OpcNextPage, err
+	})
+	return attachments, err
 }
 
 func GetVnic(ctx context.Context, c core.VirtualNetworkClient, vnicID *string) (core.Vnic, error) {
@@ -2173,15 +2966,29 @@ func deleteSubnet(ctx context.Context, c core.VirtualNetworkClient, id *string)
 	fmt.Println("subnet deleted")
 }
 
-func getInstance(instanceId *string) (core.Instance, error) {
+func getInstance(c core.ComputeClient, instanceId *string) (core.Instance, error) {
 	req := core.GetInstanceRequest{
 		InstanceId:      instanceId,
 		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
 	}
-	resp, err := computeClient.GetInstance(ctx, req)
+	resp, err := c.GetInstance(ctx, req)
 	return resp.Instance, err
 }
 
+// 根据按钮中的编号获取实例，已终止的实例返回错误
+func getInstanceByKey(instanceKey int) (core.Instance, error) {
+	id, err := lookupOcid(instanceKey)
+	if err != nil {
+		return core.Instance{}, err
+	}
+	ins, err := getInstance(computeClient, id)
+	if err == nil && (ins.LifecycleState == core.InstanceLifecycleStateTerminating ||
+		ins.LifecycleState == core.InstanceLifecycleStateTerminated) {
+		err = fmt.Errorf("实例 '%s' 已终止", *ins.DisplayName)
+	}
+	return ins, err
+}
+
 func updateInstance(instanceId *string, displayName *string, ocpus, memoryInGBs *float32,
 	details []core.InstanceAgentPluginConfigDetails, disable *bool) (core.UpdateInstanceResponse, error) {
 	updateInstanceDetails := core.UpdateInstanceDetails{}
@@ -2223,13 +3030,13 @@ func updateInstance(instanceId *string, displayName *string, ocpus, memoryInGBs
 	return computeClient.UpdateInstance(ctx, req)
 }
 
-func instanceAction(instanceId *string, action core.InstanceActionActionEnum) (ins core.Instance, err error) {
+func instanceAction(c core.ComputeClient, instanceId *string, action core.InstanceActionActionEnum) (ins core.Instance, err error) {
 	req := core.InstanceActionRequest{
 		InstanceId:      instanceId,
 		Action:          action,
 		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
 	}
-	resp, err := computeClient.InstanceAction(ctx, req)
+	resp, err := c.InstanceAction(ctx, req)
 	ins = resp.Instance
 	return
 }
@@ -2267,12 +3074,12 @@ func changePublicIp(vnics []core.Vnic) (publicIp core.PublicIp, err error) {
 	}
 	time.Sleep(3 * time.Second)
 	fmt.Println("正在创建公共IP...")
-	publicIp, err = createPublicIp(privateIp.Id)
+	publicIp, err = createPublicIp(privateIp.CompartmentId, privateIp.Id, newResourceJob())
 	return
 }
 
 func getInstanceVnics(instanceId *string) (vnics []core.Vnic, err error) {
-	vnicAttachments, _, err := ListVnicAttachments(ctx, computeClient, instanceId, nil)
+	vnicAttachments, err := ListVnicAttachments(ctx, computeClient, getCompartmentId(), instanceId)
 	if err != nil {
 		return
 	}
@@ -2304,11 +3111,17 @@ func getPrivateIps(vnicId *string) ([]core.PrivateIp, error) {
 		VnicId:          vnicId,
 		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
 	}
-	resp, err := networkClient.ListPrivateIps(ctx, req)
-	if err == nil && (resp.Items == nil || len(resp.Items) == 0) {
+	var privateIps []core.PrivateIp
+	err := listAllPages(func(page *string) (*string, error) {
+		req.Page = page
+		resp, err := networkClient.ListPrivateIps(ctx, req)
+		privateIps = append(privateIps, resp.Items...)
+		return resp.OpcNextPage, err
+	})
+	if err == nil && len(privateIps) == 0 {
 		err = errors.New("私有IP为空")
 	}
-	return resp.Items, err
+	return privateIps, err
 }
 
 // 获取分配给指定私有IP的公共IP
@@ -2338,13 +3151,15 @@ func deletePublicIp(publicIpId *string) (core.DeletePublicIpResponse, error) {
 // 通过Lifetime指定创建临时公共IP还是保留公共IP。
 // 创建临时公共IP，必须指定privateIpId，将临时公共IP分配给指定私有IP。
 // 创建保留公共IP，可以不指定privateIpId。稍后可以使用updatePublicIp方法分配给私有IP。
-func createPublicIp(privateIpId *string) (core.PublicIp, error) {
+func createPublicIp(compartmentId, privateIpId *string, job *resourceJob) (core.PublicIp, error) {
 	var publicIp core.PublicIp
 	req := core.CreatePublicIpRequest{
 		CreatePublicIpDetails: core.CreatePublicIpDetails{
-			CompartmentId: common.String(oracle.Tenancy),
+			CompartmentId: compartmentId,
 			Lifetime:      core.CreatePublicIpDetailsLifetimeEphemeral,
 			PrivateIpId:   privateIpId,
+			FreeformTags:  job.freeformTags(),
+			DefinedTags:   job.definedTags(),
 		},
 		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
 	}
@@ -2369,12 +3184,12 @@ func updatePublicIp(publicIpId *string, privateIpId *string) (core.PublicIp, err
 }
 
 // 根据实例OCID获取公共IP
-func getInstancePublicIps(instanceId *string) (ips []string, err error) {
+func getInstancePublicIps(s *session, instanceId *string) (ips []string, err error) {
 	// 多次尝试，避免刚抢购到实例，实例正在预配获取不到公共IP。
 	var ins core.Instance
 	for i := 0; i < 100; i++ {
 		if ins.LifecycleState != core.InstanceLifecycleStateRunning {
-			ins, err = getInstance(instanceId)
+			ins, err = getInstance(s.compute, instanceId)
 			if err != nil {
 				continue
 			}
@@ -2388,13 +3203,13 @@ func getInstancePublicIps(instanceId *string) (ips []string, err error) {
 		}
 
 		var vnicAttachments []core.VnicAttachment
-		vnicAttachments, _, err = ListVnicAttachments(ctx, computeClient, instanceId, nil)
+		vnicAttachments, err = ListVnicAttachments(ctx, s.compute, common.String(s.compartmentId), instanceId)
 		if err != nil {
 			continue
 		}
 		if len(vnicAttachments) > 0 {
 			for _, vnicAttachment := range vnicAttachments {
-				vnic, vnicErr := GetVnic(ctx, networkClient, vnicAttachment.VnicId)
+				vnic, vnicErr := GetVnic(ctx, s.network, vnicAttachment.VnicId)
 				if vnicErr != nil {
 					printf("GetVnic error: %s\n", vnicErr.Error())
 					continue
@@ -2412,25 +3227,47 @@ func getInstancePublicIps(instanceId *string) (ips []string, err error) {
 
 // 列出引导卷
 func getBootVolumes(availabilityDomain *string) ([]core.BootVolume, error) {
-	req := core.ListBootVolumesRequest{
-		AvailabilityDomain: availabilityDomain,
-		CompartmentId:      common.String(oracle.Tenancy),
-		RequestMetadata:    getCustomRequestMetadataWithRetryPolicy(),
-	}
-	resp, err := storageClient.ListBootVolumes(ctx, req)
-	if err != nil {
-		return nil, fmt.Errorf("获取引导卷列表失败: %v", err)
+	value, err := getCached(cacheKey("boot_volumes", *availabilityDomain), func() (interface{}, error) {
+		var volumes []core.BootVolume
+		for _, id := range listCompartmentIds {
+			req := core.ListBootVolumesRequest{
+				AvailabilityDomain: availabilityDomain,
+				CompartmentId:      common.String(id),
+				RequestMetadata:    getCustomRequestMetadataWithRetryPolicy(),
+			}
+			err := listAllPages(func(page *string) (*string, error) {
+				req.Page = page
+				resp, err := storageClient.ListBootVolumes(ctx, req)
+				volumes = append(volumes, resp.Items...)
+				return resp.OpcNextPage, err
+			})
+			if err != nil {
+				return nil, fmt.Errorf("获取引导卷列表失败: %v", err)
+			}
+		}
+		return volumes, nil
+	})
+	result, _ := value.([]core.BootVolume)
+	return result, err
+}
+
+// 列出所有可用性域中的引导卷
+func getAllBootVolumes() []core.BootVolume {
+	var bootVolumes []core.BootVolume
+	for _, ad := range availabilityDomains {
+		volumes, _ := getBootVolumes(ad.Name)
+		bootVolumes = append(bootVolumes, volumes...)
 	}
-	return resp.Items, nil
+	return bootVolumes
 }
 
 // 获取指定引导卷
-func getBootVolume(bootVolumeId *string) (core.BootVolume, error) {
+func getBootVolume(c core.BlockstorageClient, bootVolumeId *string) (core.BootVolume, error) {
 	req := core.GetBootVolumeRequest{
 		BootVolumeId:    bootVolumeId,
 		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
 	}
-	resp, err := storageClient.GetBootVolume(ctx, req)
+	resp, err := c.GetBootVolume(ctx, req)
 	return resp.BootVolume, err
 }
 
@@ -2463,36 +3300,119 @@ func deleteBootVolume(bootVolumeId *string) (*http.Response, error) {
 }
 
 // 分离引导卷
-func detachBootVolume(bootVolumeAttachmentId *string) (*http.Response, error) {
+func detachBootVolume(c core.ComputeClient, bootVolumeAttachmentId *string) (*http.Response, error) {
 	req := core.DetachBootVolumeRequest{
 		BootVolumeAttachmentId: bootVolumeAttachmentId,
 		RequestMetadata:        getCustomRequestMetadataWithRetryPolicy(),
 	}
-	resp, err := computeClient.DetachBootVolume(ctx, req)
+	resp, err := c.DetachBootVolume(ctx, req)
 	return resp.RawResponse, err
 }
 
-// 获取引导卷附件
-func listBootVolumeAttachments(availabilityDomain, compartmentId, bootVolumeId *string) ([]core.BootVolumeAttachment, error) {
+// 获取引导卷附件（不包括已分离的附件）
+func listBootVolumeAttachments(c core.ComputeClient, availabilityDomain, compartmentId, bootVolumeId *string) ([]core.BootVolumeAttachment, error) {
 	req := core.ListBootVolumeAttachmentsRequest{
 		AvailabilityDomain: availabilityDomain,
 		CompartmentId:      compartmentId,
 		BootVolumeId:       bootVolumeId,
 		RequestMetadata:    getCustomRequestMetadataWithRetryPolicy(),
 	}
-	resp, err := computeClient.ListBootVolumeAttachments(ctx, req)
-	return resp.Items, err
+	return listAllBootVolumeAttachments(c, req)
+}
+
+// 获取实例的引导卷附件（不包括已分离的附件）
+func listInstanceBootVolumeAttachments(c core.ComputeClient, ins core.Instance) ([]core.BootVolumeAttachment, error) {
+	req := core.ListBootVolumeAttachmentsRequest{
+		AvailabilityDomain: ins.AvailabilityDomain,
+		CompartmentId:      ins.CompartmentId,
+		InstanceId:         ins.Id,
+		RequestMetadata:    getCustomRequestMetadataWithRetryPolicy(),
+	}
+	return listAllBootVolumeAttachments(c, req)
+}
+
+func listAllBootVolumeAttachments(c core.ComputeClient, req core.ListBootVolumeAttachmentsRequest) ([]core.BootVolumeAttachment, error) {
+	var items []core.BootVolumeAttachment
+	err := listAllPages(func(page *string) (*string, error) {
+		req.Page = page
+		resp, err := c.ListBootVolumeAttachments(ctx, req)
+		items = append(items, resp.Items...)
+		return resp.OpcNextPage, err
+	})
+	return filterBootVolumeAttachments(items), err
+}
+
+func filterBootVolumeAttachments(items []core.BootVolumeAttachment) []core.BootVolumeAttachment {
+	var attachments []core.BootVolumeAttachment
+	for _, attachment := range items {
+		if attachment.LifecycleState != core.BootVolumeAttachmentLifecycleStateDetached {
+			attachments = append(attachments, attachment)
+		}
+	}
+	return attachments
+}
+
+// 附加引导卷，实例必须处于停止状态且没有附加引导卷
+func attachBootVolume(c core.ComputeClient, instanceId, bootVolumeId *string) (core.BootVolumeAttachment, error) {
+	req := core.AttachBootVolumeRequest{
+		AttachBootVolumeDetails: core.AttachBootVolumeDetails{
+			InstanceId:   instanceId,
+			BootVolumeId: bootVolumeId,
+		},
+		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+	}
+	resp, err := c.AttachBootVolume(ctx, req)
+	return resp.BootVolumeAttachment, err
+}
+
+// 等待引导卷附件达到指定状态
+func waitBootVolumeAttachmentState(c core.ComputeClient, attachmentId *string, state core.BootVolumeAttachmentLifecycleStateEnum) error {
+	var current core.BootVolumeAttachmentLifecycleStateEnum
+	for i := 0; i < 100; i++ {
+		req := core.GetBootVolumeAttachmentRequest{
+			BootVolumeAttachmentId: attachmentId,
+			RequestMetadata:        getCustomRequestMetadataWithRetryPolicy(),
+		}
+		resp, err := c.GetBootVolumeAttachment(ctx, req)
+		if err != nil {
+			return err
+		}
+		current = resp.LifecycleState
+		if current == state {
+			return nil
+		}
+		time.Sleep(3 * time.Second)
+	}
+	return fmt.Errorf("等待引导卷附件状态变为 %s 超时, 当前状态: %s", state, current)
+}
+
+// 等待实例达到指定状态
+func waitInstanceState(c core.ComputeClient, instanceId *string, state core.InstanceLifecycleStateEnum) error {
+	var current core.InstanceLifecycleStateEnum
+	for i := 0; i < 100; i++ {
+		ins, err := getInstance(c, instanceId)
+		if err != nil {
+			return err
+		}
+		current = ins.LifecycleState
+		if current == state {
+			return nil
+		}
+		time.Sleep(3 * time.Second)
+	}
+	return fmt.Errorf("等待实例状态变为 %s 超时, 当前状态: %s", getInstanceState(state), getInstanceState(current))
 }
 
 func sendMessage(name, text string) (msg Message, err error) {
-	if token != "" && chat_id != "" {
+	c := getConfig()
+	if c.token != "" && c.chatId != "" {
 		data := url.Values{
 			"parse_mode": {"Markdown"},
-			"chat_id":    {chat_id},
+			"chat_id":    {c.chatId},
 			"text":       {"🔰*甲骨文通知* " + name + "\n" + text},
 		}
 		var req *http.Request
-		req, err = http.NewRequest(http.MethodPost, sendMessageUrl, strings.NewReader(data.Encode()))
+		req, err = http.NewRequest(http.MethodPost, "https://api.telegram.org/bot"+c.token+"/sendMessage", strings.NewReader(data.Encode()))
 		if err != nil {
 			return
 		}
@@ -2522,15 +3442,16 @@ func sendMessage(name, text string) (msg Message, err error) {
 }
 
 func editMessage(messageId int, name, text string) (msg Message, err error) {
-	if token != "" && chat_id != "" {
+	c := getConfig()
+	if c.token != "" && c.chatId != "" {
 		data := url.Values{
 			"parse_mode": {"Markdown"},
-			"chat_id":    {chat_id},
+			"chat_id":    {c.chatId},
 			"message_id": {strconv.Itoa(messageId)},
 			"text":       {"🔰*甲骨文通知* " + name + "\n" + text},
 		}
 		var req *http.Request
-		req, err = http.NewRequest(http.MethodPost, editMessageUrl, strings.NewReader(data.Encode()))
+		req, err = http.NewRequest(http.MethodPost, "https://api.telegram.org/bot"+c.token+"/editMessageText", strings.NewReader(data.Encode()))
 		if err != nil {
 			return
 		}
@@ -2561,7 +3482,7 @@ func editMessage(messageId int, name, text string) (msg Message, err error) {
 }
 
 func setProxyOrNot(client *common.BaseClient) {
-	if proxy != "" {
+	if proxy := getConfig().proxy; proxy != "" {
 		proxyURL, err := url.Parse(proxy)
 		if err != nil {
 			printlnErr("URL parse failed", err.Error())
@@ -2669,14 +3590,14 @@ func getCustomRequestMetadataWithRetryPolicy() common.RequestMetadata {
 func getCustomRetryPolicy() *common.RetryPolicy {
 	// how many times to do the retry
 	attempts := uint(3)
-	// retry for all non-200 status code
-	retryOnAllNon200ResponseCodes := func(r common.OCIOperationResponse) bool {
-		return !(r.Error == nil && 199 < r.Response.HTTPResponse().StatusCode && r.Response.HTTPResponse().StatusCode < 300)
+	// 只重试网络错误、限流 (429) 和服务端错误，容量不足时重试也不会成功，由调用方处理
+	retryOnRetryableErrors := func(r common.OCIOperationResponse) bool {
+		return common.IsErrorRetryableByDefault(r.Error) && !isOutOfHostCapacity(r.Error)
 	}
 	policy := common.NewRetryPolicyWithOptions(
 		// only base off DefaultRetryPolicyWithoutEventualConsistency() if we're not handling eventual consistency
 		common.WithConditionalOption(!false, common.ReplaceWithValuesFromRetryPolicy(common.DefaultRetryPolicyWithoutEventualConsistency())),
 		common.WithMaximumNumberAttempts(attempts),
-		common.WithShouldRetryOperation(retryOnAllNon200ResponseCodes))
+		common.WithShouldRetryOperation(retryOnRetryableErrors))
 	return &policy
 }
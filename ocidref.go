@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// 回调数据最长 64 字节，放不下资源的 OCID。删除、恢复等操作的按钮中使用短编号，
+// 编号对应的 OCID 保存在内存中，列表内容变化后按钮仍然对应原来的资源。
+const ocidRefTTL = 24 * time.Hour
+
+type ocidRef struct {
+	id      string
+	account string // 资源所属的账号和区域
+	region  string
+	created time.Time
+}
+
+var (
+	ocidRefs      = make(map[int]ocidRef)
+	ocidRefKeys   = make(map[string]int) // OCID -> 编号，同一资源重复使用相同的编号
+	ocidRefNext   int
+	ocidRefsMutex sync.Mutex
+)
+
+// 返回当前账号中资源 OCID 对应的编号，同时清除过期的编号
+func ocidKey(id *string) int {
+	ocidRefsMutex.Lock()
+	defer ocidRefsMutex.Unlock()
+	for key, ref := range ocidRefs {
+		if time.Since(ref.created) > ocidRefTTL {
+			delete(ocidRefs, key)
+			delete(ocidRefKeys, ref.id)
+		}
+	}
+	if key, ok := ocidRefKeys[*id]; ok {
+		ref := ocidRefs[key]
+		if ref.account == oracleSectionName && ref.region == oracle.Region {
+			ref.created = time.Now()
+			ocidRefs[key] = ref
+			return key
+		}
+	}
+	ocidRefNext++
+	ocidRefs[ocidRefNext] = ocidRef{id: *id, account: oracleSectionName, region: oracle.Region, created: time.Now()}
+	ocidRefKeys[*id] = ocidRefNext
+	return ocidRefNext
+}
+
+// 根据编号获取资源 OCID，编号过期或不属于当前账号和区域时返回错误
+func lookupOcid(key int) (*string, error) {
+	ocidRefsMutex.Lock()
+	ref, ok := ocidRefs[key]
+	ocidRefsMutex.Unlock()
+	if !ok || time.Since(ref.created) > ocidRefTTL {
+		return nil, errors.New("按钮已过期，请重新打开列表")
+	}
+	if ref.account != oracleSectionName || ref.region != oracle.Region {
+		return nil, errors.New("该资源不属于当前账号和区域，请重新打开列表")
+	}
+	id := ref.id
+	return &id, nil
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-ini/ini"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+	"github.com/oracle/oci-go-sdk/v65/usageapi"
+)
+
+// Always Free 计算资源限制: Ampere A1 共 4 OCPU 和 24 GB 内存, VM.Standard.E2.1.Micro 共 2 个实例。
+const (
+	freeTierArmShape   = "VM.Standard.A1.Flex"
+	freeTierArmOcpus   = 4
+	freeTierArmMemory  = 24
+	freeTierMicroShape = "VM.Standard.E2.1.Micro"
+	freeTierMicroCount = 2
+)
+
+type AccountOverview struct {
+	Name          string
+	Regions       []string
+	States        map[core.InstanceLifecycleStateEnum]int
+	Ocpus         float32
+	MemoryInGBs   float32
+	ArmOcpus      float32
+	ArmMemoryInGB float32
+	MicroCount    int
+	BootVolumeGBs int64
+	Cost          float32
+	Currency      string
+	Errors        []string
+}
+
+// 并发查询所有账号的资源概览
+func viewAllAccountsOverviewTelegram(chatID int64) {
+	sections := getConfig().oracleSections
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("正在查询 %d 个账户...", len(sections)))
+	sentMsg, _ := bot.Send(msg)
+
+	overviews := make([]AccountOverview, len(sections))
+	var wg sync.WaitGroup
+	for i, sec := range sections {
+		wg.Add(1)
+		go func(i int, sec *ini.Section) {
+			defer wg.Done()
+			overviews[i] = getAccountOverview(sec)
+		}(i, sec)
+	}
+	wg.Wait()
+
+	var sb strings.Builder
+	sb.WriteString("📊 所有账户概览\n")
+	for _, o := range overviews {
+		sb.WriteString("\n" + formatAccountOverview(o))
+	}
+
+	editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, sb.String())
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("刷新", "all_accounts_overview"),
+			tgbotapi.NewInlineKeyboardButtonData("返回主菜单", "main_menu"),
+		),
+	)
+	editMsg.ReplyMarkup = &keyboard
+	bot.Send(editMsg)
+}
+
+func formatAccountOverview(o AccountOverview) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("[%s]\n", o.Name))
+	if len(o.Regions) > 1 {
+		sb.WriteString("区域: " + strings.Join(o.Regions, ", ") + "\n")
+	}
+
+	var states []string
+	for state, count := range o.States {
+		states = append(states, fmt.Sprintf("%s %d", getInstanceState(state), count))
+	}
+	sort.Strings(states)
+	if len(states) == 0 {
+		states = append(states, "无")
+	}
+	sb.WriteString("实例: " + strings.Join(states, ", ") + "\n")
+	sb.WriteString(fmt.Sprintf("使用中: %g OCPU, %g GB 内存\n", o.Ocpus, o.MemoryInGBs))
+	sb.WriteString(fmt.Sprintf("ARM: %g/%d OCPU, %g/%d GB", o.ArmOcpus, freeTierArmOcpus, o.ArmMemoryInGB, freeTierArmMemory))
+	if o.ArmOcpus > freeTierArmOcpus || o.ArmMemoryInGB > freeTierArmMemory {
+		sb.WriteString(" ⚠️")
+	}
+	sb.WriteString(fmt.Sprintf("\nAMD Micro: %d/%d", o.MicroCount, freeTierMicroCount))
+	if o.MicroCount > freeTierMicroCount {
+		sb.WriteString(" ⚠️")
+	}
+	sb.WriteString(fmt.Sprintf("\n引导卷: %d/%d GB", o.BootVolumeGBs, freeTierStorageGBs))
+	if o.BootVolumeGBs > freeTierStorageGBs {
+		sb.WriteString(" ⚠️")
+	}
+	sb.WriteString(fmt.Sprintf("\n本月成本: %.2f %s\n", o.Cost, o.Currency))
+	for _, e := range o.Errors {
+		sb.WriteString("❌ " + e + "\n")
+	}
+	return sb.String()
+}
+
+// 查询单个账号的资源概览。使用独立的客户端，不影响当前选择的账号；查询失败的项目记录在 Errors 中。
+func getAccountOverview(sec *ini.Section) (o AccountOverview) {
+	o.Name = sec.Name()
+	o.States = make(map[core.InstanceLifecycleStateEnum]int)
+
+	var acc Oracle
+	if err := sec.MapTo(&acc); err != nil {
+		o.Errors = append(o.Errors, "解析账号参数失败: "+err.Error())
+		return
+	}
+	p, err := getProvider(acc)
+	if err != nil {
+		o.Errors = append(o.Errors, "获取 Provider 失败: "+err.Error())
+		return
+	}
+	completeOracle(&acc, p)
+	compartmentIds, err := getAccountCompartmentIds(p, acc)
+	if err != nil {
+		o.Errors = append(o.Errors, "获取区间列表失败: "+err.Error())
+		return
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	addError := func(desc string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		o.Errors = append(o.Errors, desc+": "+err.Error())
+	}
+
+	regions, err := getAccountRegions(acc, p)
+	if err != nil {
+		addError("获取账号区域失败", err)
+	}
+	o.Regions = regions
+	for _, region := range regions {
+		regionAcc := acc
+		regionAcc.Region = region
+		rp, err := getProvider(regionAcc)
+		if err != nil {
+			addError(region, err)
+			continue
+		}
+		wg.Add(2)
+		go func(region string) {
+			defer wg.Done()
+			instances, err := listAccountInstances(rp, acc.Tenancy, compartmentIds)
+			if err != nil {
+				addError(region+" 获取实例失败", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, ins := range instances {
+				o.States[ins.LifecycleState]++
+				if ins.LifecycleState == core.InstanceLifecycleStateTerminated || ins.ShapeConfig == nil {
+					continue
+				}
+				var ocpus, memory float32
+				if ins.ShapeConfig.Ocpus != nil {
+					ocpus = *ins.ShapeConfig.Ocpus
+				}
+				if ins.ShapeConfig.MemoryInGBs != nil {
+					memory = *ins.ShapeConfig.MemoryInGBs
+				}
+				o.Ocpus += ocpus
+				o.MemoryInGBs += memory
+				switch *ins.Shape {
+				case freeTierArmShape:
+					o.ArmOcpus += ocpus
+					o.ArmMemoryInGB += memory
+				case freeTierMicroShape:
+					o.MicroCount++
+				}
+			}
+		}(region)
+		go func(region string) {
+			defer wg.Done()
+			size, err := getAccountBootVolumeGBs(rp, acc.Tenancy, compartmentIds)
+			if err != nil {
+				addError(region+" 获取引导卷失败", err)
+				return
+			}
+			mu.Lock()
+			o.BootVolumeGBs += size
+			mu.Unlock()
+		}(region)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		start, end := currMouthFirstLastDay()
+		report, err := getCostReport(p, start, end, usageapi.RequestSummarizedUsagesDetailsGranularityMonthly, "service")
+		if err != nil {
+			addError("获取成本失败", err)
+			return
+		}
+		mu.Lock()
+		o.Cost = report.Total
+		o.Currency = report.Currency
+		mu.Unlock()
+	}()
+	wg.Wait()
+	return
+}
+
+func listAccountInstances(p common.ConfigurationProvider, tenancy string, compartmentIds []string) ([]core.Instance, error) {
+	client, err := core.NewComputeClientWithConfigurationProvider(p)
+	if err != nil {
+		return nil, err
+	}
+	setProxyOrNot(&client.BaseClient)
+	setRateLimitInterceptor(&client.BaseClient, tenancy)
+	var instances []core.Instance
+	for _, id := range compartmentIds {
+		req := core.ListInstancesRequest{
+			CompartmentId:   common.String(id),
+			Limit:           common.Int(100),
+			RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+		}
+		err := listAllPages(func(page *string) (*string, error) {
+			req.Page = page
+			resp, err := client.ListInstances(ctx, req)
+			instances = append(instances, resp.Items...)
+			return resp.OpcNextPage, err
+		})
+		if err != nil {
+			return instances, err
+		}
+	}
+	return instances, nil
+}
+
+// 统计所有可用性域中未终止的引导卷总大小
+func getAccountBootVolumeGBs(p common.ConfigurationProvider, tenancy string, compartmentIds []string) (size int64, err error) {
+	idClient, err := identity.NewIdentityClientWithConfigurationProvider(p)
+	if err != nil {
+		return
+	}
+	setProxyOrNot(&idClient.BaseClient)
+	setRateLimitInterceptor(&idClient.BaseClient, tenancy)
+	client, err := core.NewBlockstorageClientWithConfigurationProvider(p)
+	if err != nil {
+		return
+	}
+	setProxyOrNot(&client.BaseClient)
+	setRateLimitInterceptor(&client.BaseClient, tenancy)
+
+	adResp, err := idClient.ListAvailabilityDomains(ctx, identity.ListAvailabilityDomainsRequest{
+		CompartmentId:   common.String(tenancy),
+		RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+	})
+	if err != nil {
+		return
+	}
+	for _, ad := range adResp.Items {
+		for _, id := range compartmentIds {
+			req := core.ListBootVolumesRequest{
+				AvailabilityDomain: ad.Name,
+				CompartmentId:      common.String(id),
+				RequestMetadata:    getCustomRequestMetadataWithRetryPolicy(),
+			}
+			err = listAllPages(func(page *string) (*string, error) {
+				req.Page = page
+				resp, err := client.ListBootVolumes(ctx, req)
+				for _, volume := range resp.Items {
+					if volume.LifecycleState != core.BootVolumeLifecycleStateTerminated && volume.SizeInGBs != nil {
+						size += *volume.SizeInGBs
+					}
+				}
+				return resp.OpcNextPage, err
+			})
+			if err != nil {
+				return
+			}
+		}
+	}
+	return
+}
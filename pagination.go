@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Telegram 列表每页显示的条目数
+const telegramPageSize = 10
+
+// Telegram 列表当前显示的页码，按列表类型保存
+var listPages = make(map[string]int)
+
+// 依次请求所有分页。fetch 使用 page 请求一页数据并返回下一页的标识，没有下一页时返回 nil
+func listAllPages(fetch func(page *string) (*string, error)) error {
+	var page *string
+	for {
+		next, err := fetch(page)
+		if err != nil || next == nil || *next == "" {
+			return err
+		}
+		page = next
+	}
+}
+
+func getPageCount(total int) int {
+	return (total + telegramPageSize - 1) / telegramPageSize
+}
+
+// 返回列表当前页的条目。indexes 为要显示的条目在完整列表中的下标，页码超出范围时显示最后一页
+func getPageIndexes(target string, indexes []int) []int {
+	page := listPages[target]
+	if pages := getPageCount(len(indexes)); page >= pages {
+		page = pages - 1
+	}
+	if page < 0 {
+		page = 0
+	}
+	listPages[target] = page
+	start := page * telegramPageSize
+	end := start + telegramPageSize
+	if end > len(indexes) {
+		end = len(indexes)
+	}
+	return indexes[start:end]
+}
+
+// 翻页按钮，只有一页时返回 nil
+func pageButtons(target string, total int) []tgbotapi.InlineKeyboardButton {
+	pages := getPageCount(total)
+	if pages <= 1 {
+		return nil
+	}
+	page := listPages[target]
+	var row []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("« 上一页", fmt.Sprintf("list_page:%s:%d", target, page-1)))
+	}
+	row = append(row, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d/%d", page+1, pages), fmt.Sprintf("list_page:%s:%d", target, page)))
+	if page < pages-1 {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("下一页 »", fmt.Sprintf("list_page:%s:%d", target, page+1)))
+	}
+	return row
+}
+
+func showListPage(chatID int64, target string, page int) {
+	listPages[target] = page
+	showListTarget(chatID, target)
+}
+
+func showListTarget(chatID int64, target string) {
+	switch target {
+	case "instances":
+		listInstancesTelegram(chatID)
+	case "boot_volumes":
+		manageBootVolumesTelegram(chatID)
+	case "block_volumes":
+		manageBlockVolumesTelegram(chatID)
+	case "boot_volume_backups":
+		manageBootVolumeBackupsTelegram(chatID)
+	case "compartments":
+		selectCompartmentTelegram(chatID)
+	}
+}
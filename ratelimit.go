@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-ini/ini"
+	"github.com/oracle/oci-go-sdk/v65/common"
+)
+
+const (
+	maxThrottleDelay     = 10 * time.Minute // 被限流 (429) 后重试间隔的上限
+	statusUpdateInterval = 30 * time.Second // 抢机状态消息的最小更新间隔
+)
+
+var (
+	rateLimiters      = make(map[string]*rateLimiter)
+	rateLimitersMutex sync.Mutex
+)
+
+func loadRateLimitConfig(c *appConfig, defSec *ini.Section) {
+	c.requestRate = defSec.Key("requestRate").MustFloat64(5)
+}
+
+// 按租户限制请求速率。同一租户的所有客户端和任务共用一个 rateLimiter
+type rateLimiter struct {
+	mutex       sync.Mutex
+	next        time.Time // 下一个请求最早的发送时间
+	pausedUntil time.Time // 被限流后暂停发送请求直到该时间
+}
+
+func getRateLimiter(tenancy string) *rateLimiter {
+	rateLimitersMutex.Lock()
+	defer rateLimitersMutex.Unlock()
+	limiter, ok := rateLimiters[tenancy]
+	if !ok {
+		limiter = &rateLimiter{}
+		rateLimiters[tenancy] = limiter
+	}
+	return limiter
+}
+
+// 等待直到允许发送下一个请求
+func (l *rateLimiter) wait() {
+	l.mutex.Lock()
+	now := time.Now()
+	at := now
+	if at.Before(l.pausedUntil) {
+		at = l.pausedUntil
+	}
+	if rate := getConfig().requestRate; rate > 0 {
+		if at.Before(l.next) {
+			at = l.next
+		}
+		l.next = at.Add(time.Duration(float64(time.Second) / rate))
+	}
+	l.mutex.Unlock()
+	time.Sleep(at.Sub(now))
+}
+
+// 暂停该租户的所有请求 d 时间
+func (l *rateLimiter) pause(d time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if until := time.Now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+// 客户端发送请求前等待所属租户的请求配额。所有访问 OCI 的客户端创建后都需要设置
+func setRateLimitInterceptor(client *common.BaseClient, tenancy string) {
+	limiter := getRateLimiter(tenancy)
+	previous := client.Interceptor
+	client.Interceptor = func(request *http.Request) error {
+		limiter.wait()
+		if previous != nil {
+			return previous(request)
+		}
+		return nil
+	}
+}
+
+func isTooManyRequests(err error) bool {
+	servErr, ok := common.IsServiceError(err)
+	return ok && servErr.GetHTTPStatusCode() == http.StatusTooManyRequests
+}
+
+func isOutOfHostCapacity(err error) bool {
+	servErr, ok := common.IsServiceError(err)
+	return ok && (strings.EqualFold(servErr.GetCode(), "OutOfHostCapacity") ||
+		strings.Contains(strings.ToLower(servErr.GetMessage()), "out of host capacity"))
+}
+
+// 抢机循环中两次尝试之间的等待时间
+type launchBackoff struct {
+	tenancy          string
+	minTime, maxTime int32 // 模板中的 minTime 和 maxTime (秒)
+	throttled        int   // 连续被限流的次数
+	delay            time.Duration
+	reason           string
+}
+
+// 根据上一次创建的结果计算等待时间:
+// 被限流时按指数退避并加入随机抖动，同时暂停该租户的其他请求；
+// 容量不足时在 minTime 到 minTime 与 maxTime 的中间值之间随机等待，加快重试；
+// 其他情况在 minTime 到 maxTime 之间随机等待。
+func (b *launchBackoff) next(err error) time.Duration {
+	switch {
+	case isTooManyRequests(err):
+		b.throttled++
+		base := time.Duration(b.maxTime) * time.Second
+		if base < time.Second {
+			base = time.Second
+		}
+		delay := maxThrottleDelay
+		if b.throttled < 20 {
+			if d := base << uint(b.throttled); d > 0 && d < maxThrottleDelay {
+				delay = d
+			}
+		}
+		b.delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+		b.reason = fmt.Sprintf("请求过多, 第 %d 次退避", b.throttled)
+		getRateLimiter(b.tenancy).pause(b.delay)
+	case isOutOfHostCapacity(err):
+		b.throttled = 0
+		b.delay = randomSecond(b.minTime, (b.minTime+b.maxTime)/2)
+		b.reason = "容量不足, 加快重试"
+	default:
+		b.throttled = 0
+		b.delay = randomSecond(b.minTime, b.maxTime)
+		b.reason = "正常间隔"
+	}
+	return b.delay
+}
+
+func (b *launchBackoff) wait() {
+	printf("Sleep %s...\n", b)
+	time.Sleep(b.delay)
+}
+
+// 当前等待时间，用于状态消息
+func (b *launchBackoff) String() string {
+	if b.reason == "" {
+		return "无"
+	}
+	return fmt.Sprintf("%s (%s)", b.delay.Round(time.Second), b.reason)
+}
+
+func randomSecond(min, max int32) time.Duration {
+	var second int32
+	if min <= 0 || max <= 0 {
+		second = 1
+	} else if min >= max {
+		second = max
+	} else {
+		second = rand.Int31n(max-min) + min
+	}
+	return time.Duration(second) * time.Second
+}
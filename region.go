@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+// 当前账号可用的区域，第一个为 region 设置的默认区域
+var accountRegions []string
+
+// 获取账号的区域列表。regions 可以填写以逗号分隔的区域，或填写 subscribed 使用租户已订阅的所有区域；未设置时只使用 region。
+func getAccountRegions(o Oracle, p common.ConfigurationProvider) ([]string, error) {
+	regions := []string{o.Region}
+	var names []string
+	switch strings.TrimSpace(o.Regions) {
+	case "":
+		return regions, nil
+	case "subscribed":
+		client, err := identity.NewIdentityClientWithConfigurationProvider(p)
+		if err != nil {
+			return regions, err
+		}
+		setProxyOrNot(&client.BaseClient)
+		setRateLimitInterceptor(&client.BaseClient, o.Tenancy)
+		resp, err := client.ListRegionSubscriptions(ctx, identity.ListRegionSubscriptionsRequest{
+			TenancyId:       common.String(o.Tenancy),
+			RequestMetadata: getCustomRequestMetadataWithRetryPolicy(),
+		})
+		if err != nil {
+			return regions, err
+		}
+		for _, r := range resp.Items {
+			if r.Status == identity.RegionSubscriptionStatusReady {
+				names = append(names, *r.RegionName)
+			}
+		}
+	default:
+		names = strings.Split(o.Regions, ",")
+	}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || containsString(regions, name) {
+			continue
+		}
+		regions = append(regions, name)
+	}
+	return regions, nil
+}
+
+// 切换当前账号的区域并重新创建客户端
+func switchRegion(region string) error {
+	previous := oracle.Region
+	oracle.Region = region
+	if err := initClients(); err != nil {
+		oracle.Region = previous
+		initClients()
+		return err
+	}
+	return nil
+}
+
+func selectRegionTelegram(chatID int64) {
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for i, region := range accountRegions {
+		text := region
+		if region == oracle.Region {
+			text += " (当前)"
+		}
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(text, fmt.Sprintf("select_region:%d", i)),
+		))
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("返回", "account_menu"),
+	))
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("账户：%s\n请选择区域：", oracleSection.Name()))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	bot.Send(msg)
+}
+
+func selectRegion(chatID int64, regionIndex int) {
+	if regionIndex < 0 || regionIndex >= len(accountRegions) {
+		sendErrorMessage(chatID, "无效的区域选择")
+		return
+	}
+	if err := switchRegion(accountRegions[regionIndex]); err != nil {
+		sendErrorMessage(chatID, "切换区域失败: "+err.Error())
+		return
+	}
+	sendAccountMenu(chatID)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
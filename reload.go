@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+	"time"
+
+	"github.com/go-ini/ini"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const configWatchInterval = 5 * time.Second
+
+// 重新加载配置的请求，值为触发来源
+var reloadRequests = make(chan string, 1)
+
+// 请求重新加载配置。请求在主循环中执行，未处理的请求会合并为一次
+func requestReload(source string) {
+	select {
+	case reloadRequests <- source:
+	default:
+	}
+}
+
+// 收到 SIGHUP 信号时重新加载配置
+func watchReloadSignal() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGHUP)
+	for range c {
+		requestReload("SIGHUP")
+	}
+}
+
+// 定期检查配置文件的修改时间，修改后重新加载配置
+func watchConfigFile() {
+	var lastModTime time.Time
+	if info, err := os.Stat(configFilePath); err == nil {
+		lastModTime = info.ModTime()
+	}
+	for {
+		time.Sleep(configWatchInterval)
+		info, err := os.Stat(configFilePath)
+		if err != nil || info.ModTime().Equal(lastModTime) {
+			continue
+		}
+		lastModTime = info.ModTime()
+		if getConfig().watchConfig {
+			requestReload("配置文件已修改")
+		}
+	}
+}
+
+// 重新读取并校验配置文件，成功后替换当前配置。
+// 正在执行的任务使用开始时的实例配置和客户端，不受影响；当前选择的账号按名称对应到新的配置。
+// 返回当前选择的账号需要提示用户的变化
+func reloadConfig() (notice string, err error) {
+	cfg, err := loadConfigFile(configFilePath)
+	if err != nil {
+		return
+	}
+	if err = configIssuesError(validateConfig(cfg)); err != nil {
+		return
+	}
+	old := getConfig()
+	if err = loadConfig(cfg); err != nil {
+		return
+	}
+	if oracleSection != nil {
+		notice = reloadSelectedAccount(old.proxy != getConfig().proxy)
+	}
+	if getConfig().token != old.token {
+		log.Println("Telegram token 已修改，需要重启程序后生效")
+	}
+	return
+}
+
+// 将当前选择的账号对应到新的配置。账号参数或代理修改后重新初始化账号，
+// 账号被删除或初始化失败时取消选择，需要用户重新选择账号
+func reloadSelectedAccount(proxyChanged bool) string {
+	name := oracleSection.Name()
+	var sec *ini.Section
+	for _, s := range getConfig().oracleSections {
+		if s.Name() == name {
+			sec = s
+			break
+		}
+	}
+	if sec == nil {
+		oracleSection = nil
+		clearAllUserStates()
+		return fmt.Sprintf("账号 [%s] 已从配置文件中删除，请重新选择账号", name)
+	}
+	changed := proxyChanged || !reflect.DeepEqual(oracleSection.KeysHash(), sec.KeysHash())
+	oracleSection = sec
+	if !changed {
+		return ""
+	}
+	if err := initVar(sec); err != nil {
+		oracleSection = nil
+		clearAllUserStates()
+		return fmt.Sprintf("账号 [%s] 的配置已修改，重新初始化失败，请重新选择账号\n%s", name, err.Error())
+	}
+	return fmt.Sprintf("账号 [%s] 的配置已修改，已重新初始化，当前区域: %s", name, oracle.Region)
+}
+
+// 重新加载配置并通知结果，chatID 为 0 时通过 sendMessage 通知
+func reloadConfigNotify(chatID int64, source string) {
+	var text string
+	if notice, err := reloadConfig(); err != nil {
+		printlnErr("重新加载配置失败", err.Error())
+		text = fmt.Sprintf("❌ 重新加载配置失败 (%s)，继续使用当前配置\n%s", source, err.Error())
+	} else {
+		text = fmt.Sprintf("✅ 配置已重新加载 (%s)\n账号数量: %d", source, len(getConfig().oracleSections))
+		log.Printf("配置已重新加载 (%s)", source)
+		if notice != "" {
+			text += "\n" + notice
+			log.Println(notice)
+		}
+	}
+	if chatID != 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, text))
+	} else {
+		sendMessage("", text)
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/go-ini/ini"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+// 加密后的值格式: enc:base64(salt + nonce + 密文)
+const (
+	secretPrefix        = "enc:"
+	secretSaltSize      = 16
+	secretPassphraseEnv = "OCI_HELP_PASSPHRASE"
+)
+
+// 需要加密的配置项
+var secretKeys = []string{"token", "key_password", "key", "cloud-init-password"}
+
+// 主密码，首次需要时从环境变量读取或提示输入
+var masterPassphrase string
+
+func getMasterPassphrase() (string, error) {
+	if masterPassphrase != "" {
+		return masterPassphrase, nil
+	}
+	masterPassphrase = os.Getenv(secretPassphraseEnv)
+	if masterPassphrase == "" {
+		fmt.Print("请输入主密码: ")
+		fd := int(os.Stdin.Fd())
+		if term.IsTerminal(fd) {
+			// 终端输入时不回显主密码
+			b, err := term.ReadPassword(fd)
+			fmt.Println()
+			if err != nil {
+				return "", err
+			}
+			masterPassphrase = string(b)
+		} else {
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && err != io.EOF {
+				return "", err
+			}
+			masterPassphrase = strings.TrimRight(line, "\r\n")
+		}
+	}
+	if masterPassphrase == "" {
+		return "", errors.New("主密码不能为空")
+	}
+	return masterPassphrase, nil
+}
+
+func deriveSecretKey(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+}
+
+func encryptSecret(plaintext, passphrase string) (string, error) {
+	salt := make([]byte, secretSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key, err := deriveSecretKey(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	data := append(salt, nonce...)
+	data = gcm.Seal(data, nonce, []byte(plaintext), nil)
+	return secretPrefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+func decryptSecret(value, passphrase string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, secretPrefix))
+	if err != nil {
+		return "", err
+	}
+	if len(data) < secretSaltSize {
+		return "", errors.New("密文格式错误")
+	}
+	key, err := deriveSecretKey(passphrase, data[:secretSaltSize])
+	if err != nil {
+		return "", err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	data = data[secretSaltSize:]
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("密文格式错误")
+	}
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("解密失败，主密码错误或密文已损坏")
+	}
+	return string(plaintext), nil
+}
+
+func isSecretKey(name string) bool {
+	for _, key := range secretKeys {
+		if key == name {
+			return true
+		}
+	}
+	return false
+}
+
+// 解密配置中所有加密的值，只修改内存中的配置，不会写回文件
+func decryptConfig(cfg *ini.File) error {
+	for _, sec := range cfg.Sections() {
+		for _, key := range sec.Keys() {
+			if !strings.HasPrefix(key.Value(), secretPrefix) {
+				continue
+			}
+			passphrase, err := getMasterPassphrase()
+			if err != nil {
+				return err
+			}
+			value, err := decryptSecret(key.Value(), passphrase)
+			if err != nil {
+				return fmt.Errorf("[%s] %s: %v", sec.Name(), key.Name(), err)
+			}
+			key.SetValue(value)
+		}
+	}
+	return nil
+}
+
+// 命令行加密或解密配置文件中的敏感信息，修改前备份原配置文件
+// oci-help secrets encrypt [-embed-keys]
+// oci-help secrets decrypt
+func runSecretsCommand(cfg *ini.File, args []string) {
+	if len(args) == 0 {
+		log.Fatal("用法: oci-help secrets encrypt [-embed-keys] | decrypt")
+	}
+	fs := flag.NewFlagSet("secrets", flag.ExitOnError)
+	embedKeys := fs.Bool("embed-keys", false, "将 key_file 指向的私钥内容加密后保存到 key，并删除 key_file")
+	fs.Parse(args[1:])
+
+	passphrase, err := getMasterPassphrase()
+	if err != nil {
+		log.Fatal(err)
+	}
+	count := 0
+	switch args[0] {
+	case "encrypt":
+		for _, sec := range cfg.Sections() {
+			if *embedKeys && sec.HasKey("key_file") && !sec.HasKey("key") {
+				content, err := ioutil.ReadFile(sec.Key("key_file").Value())
+				if err != nil {
+					log.Fatalf("[%s] 读取私钥失败: %v", sec.Name(), err)
+				}
+				sec.DeleteKey("key_file")
+				sec.NewKey("key", string(content))
+			}
+			for _, key := range sec.Keys() {
+				if !isSecretKey(key.Name()) || key.Value() == "" || strings.HasPrefix(key.Value(), secretPrefix) {
+					continue
+				}
+				value, err := encryptSecret(key.Value(), passphrase)
+				if err != nil {
+					log.Fatalf("[%s] %s 加密失败: %v", sec.Name(), key.Name(), err)
+				}
+				key.SetValue(value)
+				count++
+			}
+		}
+	case "decrypt":
+		for _, sec := range cfg.Sections() {
+			for _, key := range sec.Keys() {
+				if !strings.HasPrefix(key.Value(), secretPrefix) {
+					continue
+				}
+				value, err := decryptSecret(key.Value(), passphrase)
+				if err != nil {
+					log.Fatalf("[%s] %s 解密失败: %v", sec.Name(), key.Name(), err)
+				}
+				key.SetValue(value)
+				count++
+			}
+		}
+	default:
+		log.Fatalf("未知操作: %s", args[0])
+	}
+
+	if err := backupConfigFile(); err != nil {
+		log.Fatalf("备份配置文件失败: %v", err)
+	}
+	if err := saveConfigFile(cfg); err != nil {
+		log.Fatalf("保存配置文件失败: %v", err)
+	}
+	fmt.Printf("已处理 %d 个配置项，原配置文件已备份为 %s.bak\n", count, configFilePath)
+}
+
+// 备份配置文件为 .bak
+func backupConfigFile() error {
+	content, err := ioutil.ReadFile(configFilePath)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(configFilePath+".bak", content, 0600)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+// 账号会话: 当前账号的配置、客户端、可用性域和区间的副本。
+// 后台任务 (抢机、克隆引导卷、替换引导卷、附加卷等) 在主循环中保存当前会话后再启动，
+// 执行期间切换账号、区域、区间或重新加载配置不会影响正在执行的任务。
+type session struct {
+	name                string // 账号名称
+	oracle              Oracle
+	compute             core.ComputeClient
+	network             core.VirtualNetworkClient
+	storage             core.BlockstorageClient
+	availabilityDomains []identity.AvailabilityDomain
+	compartmentId       string
+}
+
+// 保存当前账号的会话，只能在主循环中调用
+func currentSession() *session {
+	return &session{
+		name:                oracleSectionName,
+		oracle:              oracle,
+		compute:             computeClient,
+		network:             networkClient,
+		storage:             storageClient,
+		availabilityDomains: availabilityDomains,
+		compartmentId:       compartmentId,
+	}
+}
+
+// 会话是否属于当前选择的账号和区域
+func (s *session) isCurrent() bool {
+	return s.name == oracleSectionName && s.oracle.Region == oracle.Region
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"golang.org/x/crypto/ssh"
+)
+
+// 生成的 SSH 私钥保存在配置文件所在目录下的 ssh-keys 目录中
+const sshKeysDirName = "ssh-keys"
+
+type generatedSSHKey struct {
+	PublicKey  string // authorized_keys 格式的公钥
+	PrivateKey []byte // OpenSSH 格式的私钥
+}
+
+// 合并模板中的 SSH 公钥: ssh_authorized_key 中的每一行和 ssh_authorized_key_files 中的每个文件。
+// 没有任何公钥时，除非设置了 ssh_generate_key 或 allow_no_ssh_key，否则返回错误。
+func buildSSHAuthorizedKeys(ins *Instance) error {
+	var keys []string
+	for _, line := range strings.Split(ins.SSH_Public_Key, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			keys = append(keys, line)
+		}
+	}
+	for _, path := range strings.Split(ins.SSHKeyFiles, ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		data, err := ioutil.ReadFile(expandConfigPath(path))
+		if err != nil {
+			return fmt.Errorf("读取 SSH 公钥文件失败: %v", err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				keys = append(keys, line)
+			}
+		}
+	}
+	for _, key := range keys {
+		if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key)); err != nil {
+			return fmt.Errorf("SSH 公钥格式错误: %s", truncate(key, 30))
+		}
+	}
+	if len(keys) == 0 && !ins.GenerateSSHKey && !ins.AllowNoSSHKey {
+		return errors.New("模板未设置 SSH 公钥，创建的实例将无法登录。请设置 ssh_authorized_key 或 ssh_authorized_key_files，" +
+			"或者设置 ssh_generate_key=true 自动生成密钥，确实不需要公钥时设置 allow_no_ssh_key=true")
+	}
+	ins.SSH_Public_Key = strings.Join(keys, "\n")
+	return nil
+}
+
+// 路径以 ~/ 开头时使用用户目录，相对路径以配置文件所在目录为准
+func expandConfigPath(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	if !filepath.IsAbs(path) {
+		return filepath.Join(filepath.Dir(configFilePath), path)
+	}
+	return path
+}
+
+func generateSSHKey(comment string) (*generatedSSHKey, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	block, err := ssh.MarshalPrivateKey(priv, comment)
+	if err != nil {
+		return nil, err
+	}
+	return &generatedSSHKey{
+		PublicKey:  strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub))) + " " + comment,
+		PrivateKey: pem.EncodeToMemory(block),
+	}, nil
+}
+
+// 保存生成的私钥，并作为文件发送到 Telegram 管理员会话。
+// 本地保存失败时仍然发送私钥，避免私钥丢失，保存失败的原因在消息中单独说明
+func saveGeneratedSSHKey(key *generatedSSHKey, instanceName string) (path string, err error) {
+	fileName := fmt.Sprintf("%s-%s.key", sanitizeFileName(instanceName), time.Now().Format("20060102150405"))
+	caption := fmt.Sprintf("实例 %s 的 SSH 私钥", instanceName)
+	path, err = writeSSHKeyFile(fileName, key.PrivateKey)
+	if err != nil {
+		caption += "\n⚠️ 私钥保存到本地失败，请妥善保存此文件: " + err.Error()
+	}
+	if sendErr := sendDocument(fileName, key.PrivateKey, caption); sendErr != nil {
+		printlnErr("发送 SSH 私钥失败", sendErr.Error())
+	}
+	return
+}
+
+func writeSSHKeyFile(fileName string, data []byte) (string, error) {
+	dir := filepath.Join(filepath.Dir(configFilePath), sshKeysDirName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fileName)
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// 实例名称中除字母、数字、- _ . 以外的字符 (例如路径分隔符) 替换为 -
+func sanitizeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '-'
+	}, name)
+}
+
+func sendDocument(fileName string, data []byte, caption string) error {
+	chatId := getConfig().chatId
+	if bot == nil || chatId == "" {
+		return errors.New("未配置 Telegram")
+	}
+	chatID, err := strconv.ParseInt(chatId, 10, 64)
+	if err != nil {
+		return err
+	}
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: fileName, Bytes: data})
+	doc.Caption = caption
+	_, err = bot.Send(doc)
+	return err
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-ini/ini"
+)
+
+// 本工具创建的资源都会添加以下自由格式标签，用于区分手动创建的资源
+const (
+	tagCreatedByKey   = "created-by"
+	tagCreatedByValue = "oci-help"
+	tagJobIdKey       = "oci-help-job"
+)
+
+// 一次任务(例如一次抢机)中创建的资源使用相同的任务 ID 和标签
+type resourceJob struct {
+	Id           string
+	FreeformTags map[string]string
+	DefinedTags  map[string]map[string]interface{}
+}
+
+func loadTagsConfig(c *appConfig, defSec *ini.Section) error {
+	var err error
+	if c.freeformTags, err = parseFreeformTags(getKeyValue(defSec, "tags")); err != nil {
+		return fmt.Errorf("tags 配置错误: %v", err)
+	}
+	if c.definedTags, err = parseDefinedTags(getKeyValue(defSec, "defined_tags")); err != nil {
+		return fmt.Errorf("defined_tags 配置错误: %v", err)
+	}
+	return nil
+}
+
+// 解析自由格式标签，格式为 key1=value1,key2=value2
+func parseFreeformTags(s string) (map[string]string, error) {
+	tags := make(map[string]string)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		key, value, err := parseTag(item)
+		if err != nil {
+			return nil, err
+		}
+		tags[key] = value
+	}
+	return tags, nil
+}
+
+// 解析定义标签，格式为 namespace.key1=value1,namespace.key2=value2
+func parseDefinedTags(s string) (map[string]map[string]interface{}, error) {
+	tags := make(map[string]map[string]interface{})
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		i := strings.Index(item, ".")
+		if i <= 0 {
+			return nil, fmt.Errorf("定义标签格式错误: %s，应为 namespace.key=value", item)
+		}
+		key, value, err := parseTag(item[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		namespace := strings.TrimSpace(item[:i])
+		if tags[namespace] == nil {
+			tags[namespace] = make(map[string]interface{})
+		}
+		tags[namespace][key] = value
+	}
+	return tags, nil
+}
+
+func parseTag(item string) (key, value string, err error) {
+	i := strings.Index(item, "=")
+	if i < 0 {
+		return "", "", fmt.Errorf("标签格式错误: %s，应为 key=value", item)
+	}
+	key = strings.TrimSpace(item[:i])
+	value = strings.TrimSpace(item[i+1:])
+	if key == "" || len(key) > 100 || strings.ContainsAny(key, " .") {
+		return "", "", fmt.Errorf("标签名错误: %s，不能为空、不能包含空格和 . 且不能超过 100 个字符", item)
+	}
+	if len(value) > 256 {
+		return "", "", fmt.Errorf("标签 %s 的值不能超过 256 个字符", key)
+	}
+	return key, value, nil
+}
+
+func newJobId() string {
+	return fmt.Sprintf("%s-%04x", time.Now().Format("20060102-150405"), rand.Intn(0x10000))
+}
+
+// 创建只使用全局标签的任务，用于单独创建的资源 (例如备份、块存储卷)
+func newResourceJob() *resourceJob {
+	c := getConfig()
+	job := &resourceJob{
+		Id:           newJobId(),
+		FreeformTags: make(map[string]string),
+		DefinedTags:  make(map[string]map[string]interface{}),
+	}
+	job.mergeTags(c.freeformTags, c.definedTags)
+	return job
+}
+
+// 开始一个任务，任务中创建的资源使用相同的任务 ID。
+// tags 和 definedTagsText 为模板中设置的标签，与全局标签同名时使用模板中的值。
+func startJob(tags, definedTagsText string) (*resourceJob, error) {
+	freeform, err := parseFreeformTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	defined, err := parseDefinedTags(definedTagsText)
+	if err != nil {
+		return nil, err
+	}
+	job := newResourceJob()
+	job.mergeTags(freeform, defined)
+	return job, nil
+}
+
+func (j *resourceJob) mergeTags(freeform map[string]string, defined map[string]map[string]interface{}) {
+	for k, v := range freeform {
+		j.FreeformTags[k] = v
+	}
+	for namespace, values := range defined {
+		if j.DefinedTags[namespace] == nil {
+			j.DefinedTags[namespace] = make(map[string]interface{})
+		}
+		for k, v := range values {
+			j.DefinedTags[namespace][k] = v
+		}
+	}
+}
+
+// 获取创建资源时使用的自由格式标签，包含 created-by 和任务 ID
+func (j *resourceJob) freeformTags() map[string]string {
+	tags := make(map[string]string)
+	for k, v := range j.FreeformTags {
+		tags[k] = v
+	}
+	tags[tagCreatedByKey] = tagCreatedByValue
+	tags[tagJobIdKey] = j.Id
+	return tags
+}
+
+// 获取创建资源时使用的定义标签，没有设置时返回 nil
+func (j *resourceJob) definedTags() map[string]map[string]interface{} {
+	if len(j.DefinedTags) == 0 {
+		return nil
+	}
+	tags := make(map[string]map[string]interface{})
+	for namespace, values := range j.DefinedTags {
+		tags[namespace] = make(map[string]interface{})
+		for k, v := range values {
+			tags[namespace][k] = v
+		}
+	}
+	return tags
+}
+
+// 标签筛选条件格式为 key=value 或 key (只要求存在该标签)，定义标签使用 namespace.key=value
+func matchTagFilter(filter string, freeform map[string]string, defined map[string]map[string]interface{}) bool {
+	if filter == "" {
+		return true
+	}
+	key, value, hasValue := filter, "", false
+	if i := strings.Index(filter, "="); i >= 0 {
+		key, value, hasValue = strings.TrimSpace(filter[:i]), strings.TrimSpace(filter[i+1:]), true
+	}
+	if v, ok := freeform[key]; ok && (!hasValue || v == value) {
+		return true
+	}
+	if i := strings.Index(key, "."); i > 0 {
+		if v, ok := defined[key[:i]][key[i+1:]]; ok && (!hasValue || fmt.Sprint(v) == value) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatTags(freeform map[string]string, defined map[string]map[string]interface{}) string {
+	var tags []string
+	for k, v := range freeform {
+		tags = append(tags, k+"="+v)
+	}
+	for namespace, values := range defined {
+		for k, v := range values {
+			tags = append(tags, fmt.Sprintf("%s.%s=%v", namespace, k, v))
+		}
+	}
+	if len(tags) == 0 {
+		return "无"
+	}
+	sort.Strings(tags)
+	return strings.Join(tags, ", ")
+}
@@ -0,0 +1,576 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-ini/ini"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// 模板继承: [INSTANCE] 为所有模板的默认值，[INSTANCE.名称] 为通用模板，[账号.名称] 为账号专用模板。
+// 模板可以通过 extends=名称 继承另一个模板，账号专用模板优先继承同一账号中的模板。
+// 账号专用模板与通用模板同名时覆盖该通用模板，未设置 extends 时继承被覆盖的通用模板。
+const templateExtendsKey = "extends"
+
+type LaunchTemplate struct {
+	Name    string       // 模板名称
+	Account string       // 账号专用模板所属的账号，通用模板为空
+	Chain   []string     // 继承关系，从模板本身到最上层的模板
+	Source  *ini.Section // 配置文件中的模板
+	Section *ini.Section // 合并默认值和继承的配置后的模板
+}
+
+func (t LaunchTemplate) DisplayName() string {
+	if t.Account != "" {
+		return t.Name + " (账号专用)"
+	}
+	return t.Name
+}
+
+type templateSource struct {
+	name    string
+	account bool
+	sec     *ini.Section
+}
+
+// 获取当前账号可用的实例模板
+func getLaunchTemplates() ([]LaunchTemplate, error) {
+	return resolveTemplates(getConfig().instanceBaseSection, oracleSection)
+}
+
+func getLaunchTemplate(index int) (LaunchTemplate, error) {
+	templates, err := getLaunchTemplates()
+	if err != nil {
+		return LaunchTemplate{}, err
+	}
+	if index < 0 || index >= len(templates) {
+		return LaunchTemplate{}, fmt.Errorf("无效的模板选择")
+	}
+	return templates[index], nil
+}
+
+// 解析账号可用的实例模板，通用模板在前，同名的账号专用模板替换对应的通用模板，其余账号专用模板在后
+func resolveTemplates(base, account *ini.Section) ([]LaunchTemplate, error) {
+	generic := getTemplateSources(base, false)
+	var own []templateSource
+	if account != nil {
+		own = getTemplateSources(account, true)
+	}
+
+	var sources []templateSource
+	for _, g := range generic {
+		if o, ok := findTemplateSource(own, g.name); ok {
+			sources = append(sources, o)
+		} else {
+			sources = append(sources, g)
+		}
+	}
+	for _, o := range own {
+		if _, ok := findTemplateSource(generic, o.name); !ok {
+			sources = append(sources, o)
+		}
+	}
+
+	file := ini.Empty()
+	var templates []LaunchTemplate
+	for _, src := range sources {
+		chain, err := getTemplateChain(src, generic, own)
+		if err != nil {
+			return nil, err
+		}
+		sec, err := file.NewSection(src.sec.Name())
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range base.Keys() {
+			sec.NewKey(key.Name(), key.Value())
+		}
+		t := LaunchTemplate{Name: src.name, Source: src.sec, Section: sec}
+		if src.account {
+			t.Account = account.Name()
+		}
+		for i := len(chain) - 1; i >= 0; i-- {
+			for _, key := range chain[i].sec.Keys() {
+				if key.Name() != templateExtendsKey {
+					sec.NewKey(key.Name(), key.Value())
+				}
+			}
+		}
+		for _, c := range chain {
+			t.Chain = append(t.Chain, c.sec.Name())
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+func getTemplateSources(parent *ini.Section, account bool) (sources []templateSource) {
+	for _, sec := range parent.ChildSections() {
+		sources = append(sources, templateSource{
+			name:    strings.TrimPrefix(sec.Name(), parent.Name()+"."),
+			account: account,
+			sec:     sec,
+		})
+	}
+	return
+}
+
+func findTemplateSource(sources []templateSource, name string) (templateSource, bool) {
+	for _, s := range sources {
+		if s.name == name {
+			return s, true
+		}
+	}
+	return templateSource{}, false
+}
+
+// 获取模板的继承关系，检查继承的模板是否存在以及是否循环继承
+func getTemplateChain(src templateSource, generic, own []templateSource) ([]templateSource, error) {
+	chain := []templateSource{src}
+	visited := map[string]bool{src.sec.Name(): true}
+	cur := src
+	for {
+		// 只读取模板本身的配置，不使用 go-ini 从父级继承的值
+		parent, ok := cur.sec.KeysHash()[templateExtendsKey]
+		if !ok && cur.account {
+			// 未设置 extends 的账号专用模板继承同名的通用模板
+			parent = cur.name
+		}
+		if parent == "" {
+			return chain, nil
+		}
+		var next templateSource
+		found := false
+		if cur.account && parent != cur.name {
+			next, found = findTemplateSource(own, parent)
+		}
+		if !found {
+			next, found = findTemplateSource(generic, parent)
+		}
+		if !found {
+			if !ok {
+				return chain, nil
+			}
+			return nil, fmt.Errorf("[%s] 继承的模板 %s 不存在", cur.sec.Name(), parent)
+		}
+		if visited[next.sec.Name()] {
+			return nil, fmt.Errorf("[%s] 循环继承模板 %s", src.sec.Name(), next.sec.Name())
+		}
+		visited[next.sec.Name()] = true
+		chain = append(chain, next)
+		cur = next
+	}
+}
+
+// 命令行输出账号可用的模板合并默认值和继承后的完整配置
+// oci-help template [-cloud-init] <账号> [模板名称]
+func runTemplateCommand(args []string) {
+	fs := flag.NewFlagSet("template", flag.ExitOnError)
+	cloudInit := fs.Bool("cloud-init", false, "同时输出生成的 cloud-init 内容")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		log.Fatal("用法: oci-help template [-cloud-init] <账号> [模板名称]")
+	}
+	c := getConfig()
+	var account *ini.Section
+	for _, sec := range c.oracleSections {
+		if sec.Name() == fs.Arg(0) {
+			account = sec
+			break
+		}
+	}
+	if account == nil {
+		log.Fatalf("未找到账号 %s", fs.Arg(0))
+	}
+	templates, err := resolveTemplates(c.instanceBaseSection, account)
+	if err != nil {
+		log.Fatal(err)
+	}
+	found := false
+	for _, t := range templates {
+		if fs.NArg() > 1 && t.Name != fs.Arg(1) {
+			continue
+		}
+		found = true
+		fmt.Printf("# %s, 继承: %s -> [INSTANCE]\n", t.DisplayName(), strings.Join(t.Chain, " -> "))
+		fmt.Printf("[%s]\n", t.Section.Name())
+		for _, key := range t.Section.Keys() {
+			value := key.Value()
+			if isSecretKey(key.Name()) && value != "" {
+				value = "******"
+			}
+			if strings.Contains(value, "\n") {
+				value = `"""` + value + `"""`
+			}
+			fmt.Printf("%s=%s\n", key.Name(), value)
+		}
+		if *cloudInit {
+			printTemplateCloudInit(t)
+		}
+		fmt.Println()
+	}
+	if !found {
+		fmt.Fprintf(os.Stderr, "账号 %s 中未找到模板 %s\n", account.Name(), fs.Arg(1))
+		os.Exit(1)
+	}
+}
+
+// 可以在 Telegram 中修改的模板参数
+var templateFields = []struct {
+	Key  string
+	Name string
+}{
+	{"shape", "形状"},
+	{"cpus", "CPU个数"},
+	{"memoryInGBs", "内存(GB)"},
+	{"bootVolumeSizeInGBs", "引导卷大小(GB)"},
+	{"availabilityDomain", "可用性域"},
+	{"OperatingSystem", "系统"},
+	{"OperatingSystemVersion", "系统版本"},
+	{"retry", "重试次数"},
+	{"sum", "创建个数"},
+	{"tags", "标签"},
+}
+
+func getTemplateFieldName(key string) string {
+	for _, f := range templateFields {
+		if f.Key == key {
+			return f.Name
+		}
+	}
+	return key
+}
+
+// 按模板在配置文件中的名称查找模板的序号，未找到时返回 -1
+func findLaunchTemplate(sectionName string) int {
+	templates, err := getLaunchTemplates()
+	if err != nil {
+		return -1
+	}
+	for i, t := range templates {
+		if t.Source.Name() == sectionName {
+			return i
+		}
+	}
+	return -1
+}
+
+// 读取配置文件并修改，检查通过后备份原配置文件并保存，然后重新加载配置。
+// 直接读取配置文件而不是使用已加载的配置，避免将解密后的敏感信息写回文件。
+func updateConfigFile(update func(cfg *ini.File) error) error {
+	cfg, err := loadConfigFile(configFilePath)
+	if err != nil {
+		return err
+	}
+	if err := update(cfg); err != nil {
+		return err
+	}
+	if err := configIssuesError(validateConfig(cfg)); err != nil {
+		return err
+	}
+	if err := backupConfigFile(); err != nil {
+		return fmt.Errorf("备份配置文件失败: %v", err)
+	}
+	if err := saveConfigFile(cfg); err != nil {
+		return fmt.Errorf("保存配置文件失败: %v", err)
+	}
+	notice, err := reloadConfig()
+	if notice != "" {
+		sendMessage("", notice)
+	}
+	return err
+}
+
+func checkTemplateName(name string) error {
+	if name == "" || strings.ContainsAny(name, ".:[]#; \t\n") {
+		return fmt.Errorf("模板名称不能为空，且不能包含空格和 . : [ ] # ; 等字符")
+	}
+	return nil
+}
+
+func manageTemplatesTelegram(chatID int64) {
+	templates, err := getLaunchTemplates()
+	if err != nil {
+		sendErrorMessage(chatID, "解析实例模板失败: "+err.Error())
+		return
+	}
+
+	var messageText strings.Builder
+	messageText.WriteString(fmt.Sprintf("实例模板 (当前账号: %s)\n\n", oracleSectionName))
+	if len(templates) == 0 {
+		messageText.WriteString("没有找到任何实例模板。\n")
+	} else {
+		messageText.WriteString(fmt.Sprintf("%-5s %-10s %-20s %-10s %-10s\n", "序号", "模板", "配置", "CPU个数", "内存(GB)"))
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for i, t := range templates {
+		messageText.WriteString(fmt.Sprintf("%-5d %-10s %-20s %-10s %-10s\n", i+1, t.Name,
+			t.Section.Key("shape").Value(), t.Section.Key("cpus").Value(), t.Section.Key("memoryInGBs").Value()))
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(t.DisplayName(), fmt.Sprintf("template_details:%d", i)),
+		))
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("新建通用模板", "new_template:0"),
+		tgbotapi.NewInlineKeyboardButtonData("新建账号专用模板", "new_template:1"),
+	))
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("返回", "select_account:"+strconv.Itoa(getCurrentAccountIndex())),
+	))
+
+	msg := tgbotapi.NewMessage(chatID, messageText.String())
+	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	bot.Send(msg)
+}
+
+func showTemplateDetails(chatID int64, index int) {
+	t, err := getLaunchTemplate(index)
+	if err != nil {
+		sendErrorMessage(chatID, err.Error())
+		return
+	}
+	own := t.Source.KeysHash()
+
+	var messageText strings.Builder
+	messageText.WriteString(fmt.Sprintf("模板详情：%s\n\n", t.DisplayName()))
+	messageText.WriteString(fmt.Sprintf("配置: [%s]\n", t.Source.Name()))
+	messageText.WriteString(fmt.Sprintf("继承: %s -> [INSTANCE]\n", strings.Join(t.Chain, " -> ")))
+	if t.Account == "" {
+		messageText.WriteString("通用模板，修改后对所有账号生效\n")
+	}
+	messageText.WriteString("\n")
+	if ins, err := mapTemplateToInstance(t); err != nil {
+		messageText.WriteString(fmt.Sprintf("cloud-init: %s\n", err.Error()))
+	} else if ins.CloudInit != "" {
+		messageText.WriteString(fmt.Sprintf("cloud-init: %d / %d 字节\n", len(ins.CloudInit), cloudInitMaxSize))
+	}
+	for _, f := range templateFields {
+		value := t.Section.Key(f.Key).Value()
+		if value == "" {
+			value = "-"
+		}
+		if _, ok := own[f.Key]; !ok {
+			value += " (继承)"
+		}
+		messageText.WriteString(fmt.Sprintf("%s: %s\n", f.Name, value))
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for _, f := range templateFields {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("修改"+f.Name, fmt.Sprintf("template_edit:%d:%s", index, f.Key)))
+		if len(row) == 2 {
+			keyboard = append(keyboard, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		keyboard = append(keyboard, row)
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("复制模板", fmt.Sprintf("template_action:%d:clone", index)),
+		tgbotapi.NewInlineKeyboardButtonData("删除模板", fmt.Sprintf("template_action:%d:delete", index)),
+	))
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("返回", "account_action:manage_templates"),
+	))
+
+	msg := tgbotapi.NewMessage(chatID, messageText.String())
+	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	bot.Send(msg)
+}
+
+func handleTemplateAction(chatID int64, index int, action string) {
+	t, err := getLaunchTemplate(index)
+	if err != nil {
+		sendErrorMessage(chatID, err.Error())
+		return
+	}
+	switch action {
+	case "clone":
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("请输入复制后的模板名称 (复制 %s)：", t.Name))
+		msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, Selective: true}
+		bot.Send(msg)
+		setUserState(chatID, "cloning_template", index)
+	case "delete":
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("确认删除", fmt.Sprintf("confirm_delete_template:%d", index)),
+				tgbotapi.NewInlineKeyboardButtonData("取消", fmt.Sprintf("template_details:%d", index)),
+			),
+		)
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("确定要删除模板 %s [%s] 吗？", t.DisplayName(), t.Source.Name()))
+		msg.ReplyMarkup = keyboard
+		bot.Send(msg)
+	default:
+		msg := tgbotapi.NewMessage(chatID, "未知的操作")
+		bot.Send(msg)
+	}
+}
+
+func promptEditTemplate(chatID int64, index int, key string) {
+	t, err := getLaunchTemplate(index)
+	if err != nil {
+		sendErrorMessage(chatID, err.Error())
+		return
+	}
+	text := fmt.Sprintf("请输入模板 %s 的%s，当前值: %s\n输入 - 删除该参数，使用继承的值", t.Name, getTemplateFieldName(key), t.Section.Key(key).Value())
+	if key == "availabilityDomain" {
+		var names []string
+		for _, ad := range availabilityDomains {
+			names = append(names, *ad.Name)
+		}
+		text += "\n可用的可用性域: " + strings.Join(names, ", ")
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, Selective: true}
+	bot.Send(msg)
+	setUserStateData(chatID, "editing_template", index, key)
+}
+
+func handleEditTemplate(chatID int64, index int, key, value string) {
+	t, err := getLaunchTemplate(index)
+	if err != nil {
+		sendErrorMessage(chatID, err.Error())
+		return
+	}
+	value = strings.TrimSpace(value)
+	name := t.Source.Name()
+	err = updateConfigFile(func(cfg *ini.File) error {
+		sec, err := cfg.GetSection(name)
+		if err != nil {
+			return fmt.Errorf("配置文件中未找到 [%s]", name)
+		}
+		if value == "-" {
+			sec.DeleteKey(key)
+			return nil
+		}
+		if sec.HasKey(key) {
+			sec.Key(key).SetValue(value)
+			return nil
+		}
+		_, err = sec.NewKey(key, value)
+		return err
+	})
+	if err != nil {
+		sendErrorMessage(chatID, "修改模板失败: "+err.Error())
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("模板 %s 的%s已修改，下次使用该模板创建实例时生效", t.Name, getTemplateFieldName(key))))
+	showTemplateDetails(chatID, findLaunchTemplate(name))
+}
+
+func handleDeleteTemplate(chatID int64, index int) {
+	t, err := getLaunchTemplate(index)
+	if err != nil {
+		sendErrorMessage(chatID, err.Error())
+		return
+	}
+	name := t.Source.Name()
+	err = updateConfigFile(func(cfg *ini.File) error {
+		if _, err := cfg.GetSection(name); err != nil {
+			return fmt.Errorf("配置文件中未找到 [%s]", name)
+		}
+		cfg.DeleteSection(name)
+		return nil
+	})
+	if err != nil {
+		sendErrorMessage(chatID, "删除模板失败: "+err.Error())
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("模板 %s 已删除", t.DisplayName())))
+	manageTemplatesTelegram(chatID)
+}
+
+// scope 为 0 时创建通用模板，为 1 时创建当前账号专用的模板
+func promptCreateTemplate(chatID int64, scope int) {
+	msg := tgbotapi.NewMessage(chatID, "请输入新模板的名称：")
+	msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, Selective: true}
+	bot.Send(msg)
+	setUserState(chatID, "creating_template", scope)
+}
+
+// 新建的模板默认使用 Always Free 的 AMD 实例，创建后可以修改参数
+func handleCreateTemplate(chatID int64, scope int, name string) {
+	name = strings.TrimSpace(name)
+	if err := checkTemplateName(name); err != nil {
+		sendErrorMessage(chatID, err.Error())
+		return
+	}
+	parent := getConfig().instanceBaseSection.Name()
+	if scope == 1 {
+		parent = oracleSection.Name()
+	}
+	sectionName := parent + "." + name
+	err := updateConfigFile(func(cfg *ini.File) error {
+		if _, err := cfg.GetSection(sectionName); err == nil {
+			return fmt.Errorf("模板 [%s] 已存在", sectionName)
+		}
+		sec, err := cfg.NewSection(sectionName)
+		if err != nil {
+			return err
+		}
+		sec.NewKey("shape", freeTierMicroShape)
+		sec.NewKey("sum", "1")
+		return nil
+	})
+	if err != nil {
+		sendErrorMessage(chatID, "创建模板失败: "+err.Error())
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("模板 [%s] 已创建", sectionName)))
+	showTemplateDetails(chatID, findLaunchTemplate(sectionName))
+}
+
+// 复制模板本身的配置到同一位置的新模板，原模板隐式继承的模板改为通过 extends 继承
+func handleCloneTemplate(chatID int64, index int, name string) {
+	t, err := getLaunchTemplate(index)
+	if err != nil {
+		sendErrorMessage(chatID, err.Error())
+		return
+	}
+	name = strings.TrimSpace(name)
+	if err := checkTemplateName(name); err != nil {
+		sendErrorMessage(chatID, err.Error())
+		return
+	}
+	source := t.Source.Name()
+	sectionName := strings.TrimSuffix(source, t.Name) + name
+	err = updateConfigFile(func(cfg *ini.File) error {
+		src, err := cfg.GetSection(source)
+		if err != nil {
+			return fmt.Errorf("配置文件中未找到 [%s]", source)
+		}
+		if _, err := cfg.GetSection(sectionName); err == nil {
+			return fmt.Errorf("模板 [%s] 已存在", sectionName)
+		}
+		sec, err := cfg.NewSection(sectionName)
+		if err != nil {
+			return err
+		}
+		sec.Comment = src.Comment
+		for _, key := range src.Keys() {
+			newKey, err := sec.NewKey(key.Name(), key.Value())
+			if err != nil {
+				return err
+			}
+			newKey.Comment = key.Comment
+		}
+		if _, ok := src.KeysHash()[templateExtendsKey]; !ok && len(t.Chain) > 1 {
+			sec.NewKey(templateExtendsKey, t.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		sendErrorMessage(chatID, "复制模板失败: "+err.Error())
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("已将模板 %s 复制为 [%s]", t.Name, sectionName)))
+	showTemplateDetails(chatID, findLaunchTemplate(sectionName))
+}
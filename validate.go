@@ -0,0 +1,443 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-ini/ini"
+)
+
+var (
+	fingerprintRegexp = regexp.MustCompile(`^([0-9a-fA-F]{2}:){15}[0-9a-fA-F]{2}$`)
+	regionRegexp      = regexp.MustCompile(`^[a-z]+-[a-z]+-\d+$`)
+)
+
+// 账号配置中用于识别账号的配置项
+var oracleKeys = []string{"user", "fingerprint", "tenancy", "region", "key_file", "key", "key_env", "config_file", "profile", "auth"}
+
+type ConfigIssue struct {
+	Section string
+	Key     string
+	Message string
+	Warning bool
+}
+
+func (i ConfigIssue) String() string {
+	level := "❌ 错误"
+	if i.Warning {
+		level = "⚠️ 警告"
+	}
+	if i.Key == "" {
+		return fmt.Sprintf("%s [%s] %s", level, i.Section, i.Message)
+	}
+	return fmt.Sprintf("%s [%s] %s: %s", level, i.Section, i.Key, i.Message)
+}
+
+type configValidator struct {
+	issues []ConfigIssue
+}
+
+func (v *configValidator) errorf(sec *ini.Section, key, format string, a ...interface{}) {
+	v.issues = append(v.issues, ConfigIssue{Section: sec.Name(), Key: key, Message: fmt.Sprintf(format, a...)})
+}
+
+func (v *configValidator) warnf(sec *ini.Section, key, format string, a ...interface{}) {
+	v.issues = append(v.issues, ConfigIssue{Section: sec.Name(), Key: key, Message: fmt.Sprintf(format, a...), Warning: true})
+}
+
+// 检查配置文件中的全局设置、账号和实例模板
+func validateConfig(cfg *ini.File) []ConfigIssue {
+	v := &configValidator{}
+	v.validateDefault(cfg.Section(ini.DefaultSection))
+
+	var accounts []*ini.Section
+	for _, sec := range cfg.Sections() {
+		if sec.Name() == ini.DefaultSection || sec.Name() == "INSTANCE" || len(sec.ParentKeys()) > 0 {
+			continue
+		}
+		if isOracleSection(sec) {
+			accounts = append(accounts, sec)
+			v.validateAccount(sec)
+		} else if hasAnyKey(sec, oracleKeys) {
+			v.errorf(sec, "", "账号配置不完整，需要 user、fingerprint、tenancy、region 和私钥 (key_file / key / key_env)，或者 profile / auth。该账号不会被加载")
+		}
+	}
+	if len(accounts) == 0 {
+		v.issues = append(v.issues, ConfigIssue{Section: ini.DefaultSection, Message: "未找到有效的账号配置"})
+	}
+
+	// 检查每个账号合并默认值和继承后的模板，通用模板只检查一次
+	instanceSection := cfg.Section("INSTANCE")
+	v.validateTemplate(instanceSection, false)
+	validated := make(map[string]bool)
+	for _, account := range append([]*ini.Section{nil}, accounts...) {
+		templates, err := resolveTemplates(instanceSection, account)
+		if err != nil {
+			if !validated[err.Error()] {
+				validated[err.Error()] = true
+				v.issues = append(v.issues, ConfigIssue{Section: "INSTANCE", Key: templateExtendsKey, Message: err.Error()})
+			}
+			continue
+		}
+		for _, t := range templates {
+			if !validated[t.Section.Name()] {
+				validated[t.Section.Name()] = true
+				v.validateTemplate(t.Section, true)
+			}
+		}
+	}
+	return v.issues
+}
+
+func (v *configValidator) validateDefault(sec *ini.Section) {
+	v.checkInlineComments(sec)
+	if proxy := getKeyValue(sec, "proxy"); proxy != "" {
+		u, err := url.Parse(proxy)
+		if err != nil || (u.Scheme != "socks5" && u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.errorf(sec, "proxy", "代理地址格式错误，例如 socks5://127.0.0.1:7890 或 http://127.0.0.1:7890")
+		}
+	}
+	token := getKeyValue(sec, "token")
+	chatId := getKeyValue(sec, "chat_id")
+	if (token == "") != (chatId == "") {
+		v.warnf(sec, "token", "token 和 chat_id 需要同时设置，否则不会发送 Telegram 通知")
+	}
+	if chatId != "" {
+		if _, err := strconv.ParseInt(chatId, 10, 64); err != nil {
+			v.errorf(sec, "chat_id", "必须是整数")
+		}
+	}
+	for _, key := range []string{"EACH", "budgetChargeAlert", "startupCheck", "watchConfig"} {
+		v.checkBool(sec, key)
+	}
+	if v.checkInt(sec, "budgetInterval") && getKeyInt(sec, "budgetInterval") < 0 {
+		v.errorf(sec, "budgetInterval", "不能小于 0")
+	}
+	if sec.HasKey("requestRate") && v.checkFloat(sec, "requestRate") && getKeyFloat(sec, "requestRate") < 0 {
+		v.errorf(sec, "requestRate", "不能小于 0")
+	}
+	v.checkBudget(sec)
+	v.checkTags(sec)
+}
+
+func (v *configValidator) validateAccount(sec *ini.Section) {
+	v.checkInlineComments(sec)
+	auth := getKeyValue(sec, "auth")
+	switch auth {
+	case "", authApiKey, authInstancePrincipal, authSessionToken:
+	default:
+		v.errorf(sec, "auth", "不支持的认证方式 %s，可选值: %s / %s / %s", auth, authApiKey, authInstancePrincipal, authSessionToken)
+	}
+	usesFile := getKeyValue(sec, "profile") != "" || getKeyValue(sec, "config_file") != ""
+	if (auth == "" || auth == authApiKey) && !usesFile {
+		v.checkOCID(sec, "user", "ocid1.user.")
+		v.checkOCID(sec, "tenancy", "ocid1.tenancy.")
+		if !fingerprintRegexp.MatchString(getKeyValue(sec, "fingerprint")) {
+			v.errorf(sec, "fingerprint", "格式错误，例如 12:34:56:78:90:ab:cd:ef:12:34:56:78:90:ab:cd:ef")
+		}
+		switch {
+		case getKeyValue(sec, "key") != "":
+		case getKeyValue(sec, "key_env") != "":
+			if os.Getenv(getKeyValue(sec, "key_env")) == "" {
+				v.warnf(sec, "key_env", "环境变量 %s 为空", getKeyValue(sec, "key_env"))
+			}
+		default:
+			if _, err := os.Stat(getKeyValue(sec, "key_file")); err != nil {
+				v.errorf(sec, "key_file", "私钥文件不存在: %v", err)
+			}
+		}
+	}
+	if region := getKeyValue(sec, "region"); region != "" && !regionRegexp.MatchString(region) {
+		v.errorf(sec, "region", "区域格式错误，例如 ap-singapore-1")
+	}
+	if regions := getKeyValue(sec, "regions"); regions != "" && regions != "subscribed" {
+		for _, region := range strings.Split(regions, ",") {
+			if region = strings.TrimSpace(region); !regionRegexp.MatchString(region) {
+				v.errorf(sec, "regions", "区域格式错误: %s", region)
+			}
+		}
+	}
+	if getKeyValue(sec, "compartment") != "" {
+		v.checkCompartment(sec)
+	}
+	v.checkBool(sec, "compartment_recursive")
+	v.checkBudget(sec)
+}
+
+// 检查实例模板，child 为 true 时表示 [INSTANCE.xxx] 或 [账号.xxx] 形式的模板，需要设置 shape
+func (v *configValidator) validateTemplate(sec *ini.Section, child bool) {
+	v.checkInlineComments(sec)
+	shape := getKeyValue(sec, "shape")
+	bootVolumeId := getKeyValue(sec, "bootVolumeId")
+	if child && shape == "" {
+		v.errorf(sec, "shape", "不能为空")
+	}
+
+	for _, key := range []string{"cpus", "memoryInGBs"} {
+		v.checkFloat(sec, key)
+	}
+	for _, key := range []string{"bootVolumeSizeInGBs", "sum", "each", "retry", "minTime", "maxTime"} {
+		v.checkInt(sec, key)
+	}
+
+	cpus := getKeyFloat(sec, "cpus")
+	memory := getKeyFloat(sec, "memoryInGBs")
+	if strings.Contains(strings.ToLower(shape), "flex") {
+		if cpus <= 0 {
+			v.errorf(sec, "cpus", "Flex 实例必须设置大于 0 的 OCPU 个数")
+		}
+		if memory <= 0 {
+			v.errorf(sec, "memoryInGBs", "Flex 实例必须设置大于 0 的内存大小")
+		}
+		if cpus > 0 && memory > 0 && (memory < cpus || memory > cpus*64) {
+			v.errorf(sec, "memoryInGBs", "内存大小需要在每个 OCPU 1 GB 到 64 GB 之间")
+		}
+	} else if shape != "" && (cpus > 0 || memory > 0) {
+		v.warnf(sec, "cpus", "%s 不是 Flex 实例，cpus 和 memoryInGBs 将被忽略", shape)
+	}
+
+	switch burstable := getKeyValue(sec, "burstable"); burstable {
+	case "":
+	case "1/8", "1/2":
+		if !strings.HasPrefix(shape, "VM.Standard.E") || !strings.HasSuffix(shape, ".Flex") {
+			v.warnf(sec, "burstable", "只有 VM.Standard.E*.Flex 实例支持突发性能")
+		}
+	default:
+		v.errorf(sec, "burstable", "可选值为 1/8 或 1/2")
+	}
+
+	if size := getKeyInt(sec, "bootVolumeSizeInGBs"); size != 0 && (size < 50 || size > 32768) {
+		v.errorf(sec, "bootVolumeSizeInGBs", "引导卷大小需要在 50 GB 到 32768 GB 之间")
+	}
+	if child && bootVolumeId == "" && getKeyInt(sec, "sum") < 1 && getKeyInt(sec, "each") < 1 {
+		v.errorf(sec, "sum", "创建实例个数需要大于 0")
+	}
+	if getKeyInt(sec, "each") < 0 {
+		v.errorf(sec, "each", "不能小于 0")
+	}
+	if getKeyInt(sec, "retry") < -1 {
+		v.errorf(sec, "retry", "不能小于 -1，-1 表示一直重试")
+	}
+	minTime := getKeyInt(sec, "minTime")
+	maxTime := getKeyInt(sec, "maxTime")
+	if minTime < 0 {
+		v.errorf(sec, "minTime", "不能小于 0")
+	}
+	if minTime > maxTime {
+		v.errorf(sec, "maxTime", "maxTime (%d) 不能小于 minTime (%d)", maxTime, minTime)
+	}
+
+	if bootVolumeId != "" {
+		v.checkOCID(sec, "bootVolumeId", "ocid1.bootvolume.")
+	} else if child && (getKeyValue(sec, "OperatingSystem") == "" || getKeyValue(sec, "OperatingSystemVersion") == "") {
+		v.errorf(sec, "OperatingSystemVersion", "操作系统类型和版本不能为空")
+	}
+	if getKeyValue(sec, "compartment") != "" {
+		v.checkCompartment(sec)
+	}
+
+	v.checkTags(sec)
+
+	// 加密的 cloud-init-password 在加载配置时才会解密，此时只检查其他配置
+	var ins Instance
+	if err := sec.MapTo(&ins); err == nil {
+		if strings.HasPrefix(ins.CloudInitPassword, secretPrefix) {
+			ins.CloudInitPassword = "******"
+		}
+		if err := buildCloudInit(&ins); err != nil {
+			v.errorf(sec, "cloud-init", "%v", err)
+		}
+		// 未设置公钥时不会创建实例，这里只提醒
+		allowNoSSHKey := ins.AllowNoSSHKey
+		ins.AllowNoSSHKey = true
+		if err := buildSSHAuthorizedKeys(&ins); err != nil {
+			v.errorf(sec, "ssh_authorized_key", "%v", err)
+		} else if child && ins.SSH_Public_Key == "" && !ins.GenerateSSHKey && !allowNoSSHKey {
+			v.warnf(sec, "ssh_authorized_key", "未设置 SSH 公钥，需要设置 ssh_authorized_key、ssh_authorized_key_files 或 ssh_generate_key=true 才能使用该模板创建实例")
+		}
+	}
+}
+
+func (v *configValidator) checkTags(sec *ini.Section) {
+	if _, err := parseFreeformTags(getKeyValue(sec, "tags")); err != nil {
+		v.errorf(sec, "tags", "%v", err)
+	}
+	if _, err := parseDefinedTags(getKeyValue(sec, "defined_tags")); err != nil {
+		v.errorf(sec, "defined_tags", "%v", err)
+	}
+}
+
+// go-ini 会去掉以空格开头的 # 或 ; 之后的内容，检查值中是否仍包含注释符号
+func (v *configValidator) checkInlineComments(sec *ini.Section) {
+	for _, key := range sec.Keys() {
+		value := key.Value()
+		if strings.HasPrefix(value, secretPrefix) || strings.Contains(value, "\n") {
+			continue
+		}
+		if strings.Contains(value, " #") || strings.Contains(value, " ;") {
+			v.warnf(sec, key.Name(), "值中包含注释符号，请确认注释已单独成行: %s", value)
+		}
+	}
+}
+
+func (v *configValidator) checkOCID(sec *ini.Section, key, prefix string) {
+	if !strings.HasPrefix(getKeyValue(sec, key), prefix) {
+		v.errorf(sec, key, "OCID 格式错误，应以 %s 开头", prefix)
+	}
+}
+
+func (v *configValidator) checkCompartment(sec *ini.Section) {
+	value := getKeyValue(sec, "compartment")
+	if !strings.HasPrefix(value, "ocid1.compartment.") && !strings.HasPrefix(value, "ocid1.tenancy.") {
+		v.errorf(sec, "compartment", "OCID 格式错误，应以 ocid1.compartment. 或 ocid1.tenancy. 开头")
+	}
+}
+
+func (v *configValidator) checkBudget(sec *ini.Section) {
+	if sec.HasKey("budget") && v.checkFloat(sec, "budget") && getKeyFloat(sec, "budget") < 0 {
+		v.errorf(sec, "budget", "不能小于 0")
+	}
+}
+
+// 检查整数配置项，未设置时返回 true
+func (v *configValidator) checkInt(sec *ini.Section, key string) bool {
+	if !sec.HasKey(key) || getKeyValue(sec, key) == "" {
+		return true
+	}
+	if _, err := sec.Key(key).Int64(); err != nil {
+		v.errorf(sec, key, "必须是整数: %s", getKeyValue(sec, key))
+		return false
+	}
+	return true
+}
+
+func (v *configValidator) checkFloat(sec *ini.Section, key string) bool {
+	if !sec.HasKey(key) || getKeyValue(sec, key) == "" {
+		return true
+	}
+	if _, err := sec.Key(key).Float64(); err != nil {
+		v.errorf(sec, key, "必须是数字: %s", getKeyValue(sec, key))
+		return false
+	}
+	return true
+}
+
+func (v *configValidator) checkBool(sec *ini.Section, key string) {
+	if !sec.HasKey(key) || getKeyValue(sec, key) == "" {
+		return
+	}
+	if _, err := sec.Key(key).Bool(); err != nil {
+		v.errorf(sec, key, "必须是 true 或 false: %s", getKeyValue(sec, key))
+	}
+}
+
+// 读取数字配置项，格式错误时返回 0。不使用 MustInt 等方法，避免修改配置
+func getKeyInt(sec *ini.Section, name string) int64 {
+	n, _ := strconv.ParseInt(strings.TrimSpace(getKeyValue(sec, name)), 10, 64)
+	return n
+}
+
+func getKeyFloat(sec *ini.Section, name string) float64 {
+	f, _ := strconv.ParseFloat(strings.TrimSpace(getKeyValue(sec, name)), 64)
+	return f
+}
+
+func hasAnyKey(sec *ini.Section, keys []string) bool {
+	for _, key := range keys {
+		if sec.HasKey(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
+
+// 将检查结果中的错误合并为一个 error，没有错误时返回 nil
+func configIssuesError(issues []ConfigIssue) error {
+	var errs []string
+	for _, issue := range issues {
+		if !issue.Warning {
+			errs = append(errs, issue.String())
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errors.New("配置检查未通过:\n" + strings.Join(errs, "\n"))
+}
+
+func hasConfigErrors(issues []ConfigIssue) bool {
+	for _, issue := range issues {
+		if !issue.Warning {
+			return true
+		}
+	}
+	return false
+}
+
+// 命令行检查配置文件，存在错误时以状态码 1 退出
+// oci-help validate [-online]
+func runValidateCommand(cfg *ini.File, args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	online := fs.Bool("online", false, "连接每个账号检查模板中的系统镜像是否存在")
+	fs.Parse(args)
+
+	issues := validateConfig(cfg)
+	if *online && !hasConfigErrors(issues) {
+		if err := loadConfig(cfg); err != nil {
+			log.Fatal(err)
+		}
+		issues = append(issues, validateImagesOnline()...)
+	}
+	for _, issue := range issues {
+		fmt.Println(issue.String())
+	}
+	if hasConfigErrors(issues) {
+		os.Exit(1)
+	}
+	fmt.Println("✅ 配置检查通过")
+}
+
+// 检查每个账号可用的模板中，系统镜像和实例形状的组合是否存在
+func validateImagesOnline() (issues []ConfigIssue) {
+	for _, sec := range getConfig().oracleSections {
+		oracleSection = sec
+		if err := initVar(sec); err != nil {
+			issues = append(issues, ConfigIssue{Section: sec.Name(), Message: "初始化账号失败: " + err.Error()})
+			continue
+		}
+		templates, err := getLaunchTemplates()
+		if err != nil {
+			issues = append(issues, ConfigIssue{Section: sec.Name(), Key: templateExtendsKey, Message: err.Error()})
+			continue
+		}
+		for _, t := range templates {
+			tpl := t.Section
+			ins, err := mapTemplateToInstance(t)
+			if err != nil || ins.BootVolumeId != "" {
+				continue
+			}
+			images, err := listImages(ctx, computeClient, oracle.Tenancy, ins)
+			if err != nil {
+				issues = append(issues, ConfigIssue{Section: tpl.Name(), Key: "OperatingSystemVersion", Message: fmt.Sprintf("账号 [%s] 获取镜像失败: %v", sec.Name(), err)})
+			} else if len(images) == 0 {
+				issues = append(issues, ConfigIssue{Section: tpl.Name(), Key: "OperatingSystemVersion", Message: fmt.Sprintf("账号 [%s] 中未找到 [%s %s] 支持 %s 的镜像", sec.Name(), ins.OperatingSystem, ins.OperatingSystemVersion, ins.Shape)})
+			}
+		}
+	}
+	return
+}
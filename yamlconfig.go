@@ -0,0 +1,391 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-ini/ini"
+	"gopkg.in/yaml.v3"
+)
+
+// YAML 配置文件的结构:
+//
+//	notifications:     Telegram 通知 (token, chat_id)
+//	policies:          其他全局设置 (proxy, EACH, budget 等)
+//	accounts:          账号，账号下的 templates 为该账号专用的实例模板
+//	template_defaults: 所有实例模板的默认值，对应 ini 中的 [INSTANCE]
+//	templates:         实例模板，对应 ini 中的 [INSTANCE.xxx]
+//
+// 配置项名称与 ini 相同，加载后转换为 ini 格式的配置使用。
+const (
+	yamlNotifications    = "notifications"
+	yamlPolicies         = "policies"
+	yamlAccounts         = "accounts"
+	yamlTemplateDefaults = "template_defaults"
+	yamlTemplates        = "templates"
+)
+
+// 放在 notifications 中的全局配置项，其他全局配置项放在 policies 中
+var notificationKeys = []string{"token", "chat_id"}
+
+func isYAMLConfig(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// 根据文件扩展名读取 ini 或 YAML 格式的配置文件
+func loadConfigFile(path string) (*ini.File, error) {
+	if !isYAMLConfig(path) {
+		return ini.Load(path)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return yamlToIni(data)
+}
+
+// 按配置文件原来的格式保存配置
+func saveConfigFile(cfg *ini.File) error {
+	if !isYAMLConfig(configFilePath) {
+		// 保持 key=value 的格式，不对齐等号。PrettyFormat 是全局设置，保存后恢复
+		prettyFormat := ini.PrettyFormat
+		ini.PrettyFormat = false
+		defer func() { ini.PrettyFormat = prettyFormat }()
+		return cfg.SaveTo(configFilePath)
+	}
+	data, err := iniToYAML(cfg)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(configFilePath, data, 0600)
+}
+
+func yamlToIni(data []byte) (*ini.File, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	cfg := ini.Empty()
+	if len(doc.Content) == 0 {
+		return cfg, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("第 %d 行: 配置文件顶层必须是映射", root.Line)
+	}
+	defSec := cfg.Section(ini.DefaultSection)
+	for i := 0; i < len(root.Content); i += 2 {
+		k, v := root.Content[i], root.Content[i+1]
+		var err error
+		switch k.Value {
+		case yamlNotifications, yamlPolicies:
+			err = yamlToSection(defSec, v)
+		case yamlTemplateDefaults:
+			err = yamlToNewSection(cfg, "INSTANCE", k, v)
+		case yamlTemplates:
+			err = yamlToTemplates(cfg, "INSTANCE", v)
+		case yamlAccounts:
+			err = yamlToAccounts(cfg, v)
+		default:
+			err = fmt.Errorf("第 %d 行: 未知的配置项 %s", k.Line, k.Value)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+func yamlToAccounts(cfg *ini.File, node *yaml.Node) error {
+	if isYAMLNull(node) {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("第 %d 行: %s 必须是映射", node.Line, yamlAccounts)
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		k, v := node.Content[i], node.Content[i+1]
+		sec, err := cfg.NewSection(k.Value)
+		if err != nil {
+			return err
+		}
+		sec.Comment = yamlComment(k)
+		if isYAMLNull(v) {
+			continue
+		}
+		if v.Kind != yaml.MappingNode {
+			return fmt.Errorf("第 %d 行: 账号 %s 必须是映射", v.Line, k.Value)
+		}
+		var templates *yaml.Node
+		for j := 0; j < len(v.Content); j += 2 {
+			if v.Content[j].Value == yamlTemplates {
+				templates = v.Content[j+1]
+				continue
+			}
+			if err := yamlToKey(sec, v.Content[j], v.Content[j+1]); err != nil {
+				return err
+			}
+		}
+		if templates != nil {
+			if err := yamlToTemplates(cfg, k.Value, templates); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// 实例模板转换为 [parent.模板名称]
+func yamlToTemplates(cfg *ini.File, parent string, node *yaml.Node) error {
+	if isYAMLNull(node) {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("第 %d 行: %s 必须是映射", node.Line, yamlTemplates)
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		if err := yamlToNewSection(cfg, parent+"."+node.Content[i].Value, node.Content[i], node.Content[i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func yamlToNewSection(cfg *ini.File, name string, k, v *yaml.Node) error {
+	sec, err := cfg.NewSection(name)
+	if err != nil {
+		return err
+	}
+	sec.Comment = yamlComment(k)
+	return yamlToSection(sec, v)
+}
+
+func yamlToSection(sec *ini.Section, node *yaml.Node) error {
+	if isYAMLNull(node) {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("第 %d 行: [%s] 必须是映射", node.Line, sec.Name())
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		if err := yamlToKey(sec, node.Content[i], node.Content[i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func yamlToKey(sec *ini.Section, k, v *yaml.Node) error {
+	if v.Kind != yaml.ScalarNode {
+		return fmt.Errorf("第 %d 行: %s 的值必须是字符串或数字", v.Line, k.Value)
+	}
+	value := v.Value
+	if isYAMLNull(v) {
+		value = ""
+	}
+	key, err := sec.NewKey(k.Value, value)
+	if err != nil {
+		return fmt.Errorf("第 %d 行: %v", k.Line, err)
+	}
+	comment := yamlComment(k)
+	if v.LineComment != "" {
+		comment = strings.TrimPrefix(strings.Join([]string{comment, v.LineComment}, "\n"), "\n")
+	}
+	key.Comment = comment
+	return nil
+}
+
+func isYAMLNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
+
+func yamlComment(node *yaml.Node) string {
+	return strings.TrimPrefix(strings.Join([]string{node.HeadComment, node.LineComment}, "\n"), "\n")
+}
+
+// 将 ini 格式的配置转换为 YAML，保留所有配置项和注释
+func iniToYAML(cfg *ini.File) ([]byte, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	notifications := &yaml.Node{Kind: yaml.MappingNode}
+	policies := &yaml.Node{Kind: yaml.MappingNode}
+	for _, key := range cfg.Section(ini.DefaultSection).Keys() {
+		if containsString(notificationKeys, key.Name()) {
+			appendYAMLKey(notifications, key)
+		} else {
+			appendYAMLKey(policies, key)
+		}
+	}
+	appendYAMLPair(root, yamlNotifications, "", notifications)
+	appendYAMLPair(root, yamlPolicies, "", policies)
+
+	accounts := &yaml.Node{Kind: yaml.MappingNode}
+	accountNodes := make(map[string]*yaml.Node)
+	accountTemplates := make(map[string]*yaml.Node)
+	var templateDefaults *yaml.Node
+	var templateDefaultsComment string
+	templates := &yaml.Node{Kind: yaml.MappingNode}
+	for _, sec := range cfg.Sections() {
+		name := sec.Name()
+		if name == ini.DefaultSection {
+			continue
+		}
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for _, key := range sec.Keys() {
+			appendYAMLKey(node, key)
+		}
+		parent, child := name, ""
+		if i := strings.Index(name, "."); i > 0 {
+			parent, child = name[:i], name[i+1:]
+		}
+		switch {
+		case name == "INSTANCE":
+			templateDefaults, templateDefaultsComment = node, sec.Comment
+		case parent == "INSTANCE":
+			appendYAMLPair(templates, child, sec.Comment, node)
+		case child == "":
+			accountNodes[name] = node
+			appendYAMLPair(accounts, name, sec.Comment, node)
+		default:
+			account, ok := accountNodes[parent]
+			if !ok {
+				account = &yaml.Node{Kind: yaml.MappingNode}
+				accountNodes[parent] = account
+				appendYAMLPair(accounts, parent, "", account)
+			}
+			if accountTemplates[parent] == nil {
+				accountTemplates[parent] = &yaml.Node{Kind: yaml.MappingNode}
+				appendYAMLPair(account, yamlTemplates, "", accountTemplates[parent])
+			}
+			appendYAMLPair(accountTemplates[parent], child, sec.Comment, node)
+		}
+	}
+	appendYAMLPair(root, yamlAccounts, "", accounts)
+	if templateDefaults != nil {
+		appendYAMLPair(root, yamlTemplateDefaults, templateDefaultsComment, templateDefaults)
+	}
+	appendYAMLPair(root, yamlTemplates, "", templates)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func appendYAMLKey(m *yaml.Node, key *ini.Key) {
+	// 值按原样读取，不区分类型，数字和布尔值不需要加引号
+	value := &yaml.Node{Kind: yaml.ScalarNode, Value: key.Value()}
+	switch key.Value() {
+	case "", "~", "null", "Null", "NULL":
+		value.Tag = "!!str"
+	}
+	if strings.Contains(key.Value(), "\n") {
+		value.Style = yaml.LiteralStyle
+	}
+	appendYAMLPair(m, key.Name(), key.Comment, value)
+}
+
+func appendYAMLPair(m *yaml.Node, name, comment string, value *yaml.Node) {
+	k := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name, HeadComment: yamlCommentFromIni(comment)}
+	m.Content = append(m.Content, k, value)
+}
+
+// ini 的注释可以以 # 或 ; 开头，YAML 只支持 #
+func yamlCommentFromIni(comment string) string {
+	if comment == "" {
+		return ""
+	}
+	lines := strings.Split(comment, "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, ";"):
+			lines[i] = "#" + line[1:]
+		default:
+			lines[i] = "# " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// 命令行转换配置文件格式
+// oci-help config migrate [-o oci-help.yaml] [-f]
+func runConfigCommand(cfg *ini.File, args []string) {
+	if len(args) == 0 || args[0] != "migrate" {
+		log.Fatal("用法: oci-help config migrate [-o oci-help.yaml] [-f]")
+	}
+	if isYAMLConfig(configFilePath) {
+		log.Fatalf("%s 已经是 YAML 格式", configFilePath)
+	}
+	fs := flag.NewFlagSet("config migrate", flag.ExitOnError)
+	output := fs.String("o", strings.TrimSuffix(configFilePath, filepath.Ext(configFilePath))+".yaml", "输出的 YAML 配置文件路径")
+	force := fs.Bool("f", false, "覆盖已存在的文件")
+	fs.Parse(args[1:])
+
+	if _, err := os.Stat(*output); err == nil && !*force {
+		log.Fatalf("%s 已存在，使用 -f 覆盖", *output)
+	}
+	data, err := iniToYAML(cfg)
+	if err != nil {
+		log.Fatalf("转换配置失败: %v", err)
+	}
+	// 转换后重新读取并与原配置比较，确保没有丢失配置项
+	converted, err := yamlToIni(data)
+	if err != nil {
+		log.Fatalf("转换后的配置无法读取: %v", err)
+	}
+	if err := compareConfig(cfg, converted); err != nil {
+		log.Fatalf("转换后的配置与原配置不一致: %v", err)
+	}
+	if err := ioutil.WriteFile(*output, data, 0600); err != nil {
+		log.Fatalf("保存配置文件失败: %v", err)
+	}
+	fmt.Printf("已将 %s 转换为 %s，使用 -c %s 运行程序\n", configFilePath, *output, *output)
+}
+
+// 比较两个配置是否包含相同的配置节和配置项
+func compareConfig(a, b *ini.File) error {
+	for _, sec := range b.Sections() {
+		if len(sec.Keys()) == 0 && sec.Name() == ini.DefaultSection {
+			continue
+		}
+		other, err := a.GetSection(sec.Name())
+		if err != nil {
+			return fmt.Errorf("多出 [%s]", sec.Name())
+		}
+		if len(sec.Keys()) != len(other.Keys()) {
+			return fmt.Errorf("[%s] 配置项数量不一致", sec.Name())
+		}
+	}
+	// b 中的配置节在 a 中都存在且配置项数量相同，只需再检查 a 中的配置项
+	for _, sec := range a.Sections() {
+		if len(sec.Keys()) == 0 && sec.Name() == ini.DefaultSection {
+			continue
+		}
+		other, err := b.GetSection(sec.Name())
+		if err != nil {
+			return fmt.Errorf("缺少 [%s]", sec.Name())
+		}
+		if len(sec.Keys()) != len(other.Keys()) {
+			return fmt.Errorf("[%s] 配置项数量不一致", sec.Name())
+		}
+		for _, key := range sec.Keys() {
+			if !other.HasKey(key.Name()) || other.Key(key.Name()).Value() != key.Value() {
+				return fmt.Errorf("[%s] %s 的值不一致", sec.Name(), key.Name())
+			}
+		}
+	}
+	return nil
+}